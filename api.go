@@ -0,0 +1,174 @@
+package carthooks
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+)
+
+// CartHooksAPI is the API operations of Client, for code that wants to
+// depend on an interface instead of *Client. Package carthookstest provides
+// Fake, an implementation backed by an in-memory API server; since Query and
+// the other builders return types bound to a Client, other implementations
+// usually embed one too.
+type CartHooksAPI interface {
+	ListApps(ctx context.Context) ([]App, error)
+	GetApp(ctx context.Context, appID int) (*App, error)
+	CreateApp(ctx context.Context, app App) (*App, error)
+	UpdateApp(ctx context.Context, appID int, app App) (*App, error)
+
+	OpenFile(ctx context.Context, a Attachment) (io.ReadCloser, error)
+
+	ListAutomations(ctx context.Context, appID int) ([]Automation, error)
+	TriggerAutomation(ctx context.Context, appID, automationID int, payload map[string]interface{}) (*AutomationRun, error)
+	GetAutomationRun(ctx context.Context, runID int) (*AutomationRun, error)
+	WaitForRun(ctx context.Context, runID int, interval time.Duration) (*AutomationRun, error)
+
+	GetItems(ctx context.Context, refs []ItemRef, concurrency int, opts ...ItemOption) (map[ItemRef]*Item, error)
+
+	UpdateItems(appID, collectionID int, ids []int, data map[string]interface{}) (*Response, error)
+	UpdateItemsContext(ctx context.Context, appID, collectionID int, ids []int, data map[string]interface{}) (*Response, error)
+	DeleteItems(appID, collectionID int, ids []int) (*Response, error)
+	DeleteItemsContext(ctx context.Context, appID, collectionID int, ids []int) (*Response, error)
+	DeleteWhere(ctx context.Context, q *Query) (*Response, error)
+
+	BatchUpdateItems(ctx context.Context, appID, collectionID int, updates []ItemUpdate, opts ...BatchOption) (BatchResult, error)
+	BatchDeleteItems(ctx context.Context, appID, collectionID int, ids []int, opts ...BatchOption) (BatchResult, error)
+	BatchCreateItems(ctx context.Context, appID, collectionID int, data []map[string]interface{}, opts ...BatchOption) ([]*Item, error)
+
+	Get(url string) (*Response, error)
+	GetContext(ctx context.Context, url string) (*Response, error)
+	Post(url string, body map[string]any) (*Response, error)
+	PostContext(ctx context.Context, url string, body map[string]any) (*Response, error)
+	Request(method, url string, body map[string]any) (*Response, error)
+	RequestContext(ctx context.Context, method, url string, body map[string]any) (*Response, error)
+	PostForm(ctx context.Context, url string, form url.Values) (*Response, error)
+	Query(appID, collectionID int) *Query
+	GetItemByID(appID, collectionID, itemID int, opts ...ItemOption) (*Item, error)
+	GetItemByIDContext(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Item, error)
+	GetItemByIDWithResponse(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Item, *Response, error)
+	GetSubmissionToken(appID, collectionID int, options map[string]interface{}) (*Response, error)
+	GetSubmissionTokenContext(ctx context.Context, appID, collectionID int, options map[string]interface{}) (*Response, error)
+	UpdateSubmissionToken(appID, collectionID, itemID int, options map[string]interface{}) (*Response, error)
+	UpdateSubmissionTokenContext(ctx context.Context, appID, collectionID, itemID int, options map[string]interface{}) (*Response, error)
+	CreateItem(appID, collectionID int, data map[string]interface{}) (item *Item, err error)
+	CreateItemWithKey(ctx context.Context, appID, collectionID int, data map[string]interface{}, key string) (*Item, error)
+	CreateItemContext(ctx context.Context, appID, collectionID int, data map[string]interface{}) (item *Item, err error)
+	CreateItemWithResponse(ctx context.Context, appID, collectionID int, data map[string]interface{}, opts ...WriteOption) (*Item, *Response, error)
+	UpdateItem(appID, collectionID, itemID int, data map[string]interface{}) (*Response, error)
+	UpdateItemContext(ctx context.Context, appID, collectionID, itemID int, data map[string]interface{}, opts ...WriteOption) (*Response, error)
+	LockItem(appID, collectionID, itemID, lockTimeout int, lockID, subject string) (*Response, error)
+	LockItemContext(ctx context.Context, appID, collectionID, itemID, lockTimeout int, lockID, subject string) (*Response, error)
+	UnlockItem(appID, collectionID, itemID int, lockID string) (*Response, error)
+	UnlockItemContext(ctx context.Context, appID, collectionID, itemID int, lockID string) (*Response, error)
+	DeleteItem(appID, collectionID, itemID int, opts ...WriteOption) (*Response, error)
+	DeleteItemContext(ctx context.Context, appID, collectionID, itemID int, opts ...WriteOption) (*Response, error)
+	GetUploadToken() (*Response, error)
+	GetUploadTokenContext(ctx context.Context) (*Response, error)
+
+	ListCollections(ctx context.Context, appID int) ([]Collection, error)
+	GetCollection(ctx context.Context, appID, collectionID int) (*Collection, error)
+	CreateCollection(ctx context.Context, appID int, collection Collection) (*Collection, error)
+	UpdateCollection(ctx context.Context, appID, collectionID int, collection Collection) (*Collection, error)
+	DeleteCollection(ctx context.Context, appID, collectionID int) error
+	AddField(ctx context.Context, appID, collectionID int, field FieldDefinition) (*FieldDefinition, error)
+	UpdateField(ctx context.Context, appID, collectionID int, name string, field FieldDefinition) (*FieldDefinition, error)
+	DeleteField(ctx context.Context, appID, collectionID int, name string) error
+
+	CollectionCount(ctx context.Context, appID, collectionID int) (int, error)
+
+	DeleteItemIfExists(ctx context.Context, appID, collectionID, itemID int, opts ...WriteOption) (deleted bool, err error)
+
+	DistinctValues(ctx context.Context, appID, collectionID int, field string) ([]interface{}, error)
+
+	GetFileURL(ctx context.Context, fileID string, expiry time.Duration) (*FileInfo, error)
+	DownloadFile(ctx context.Context, fileID string, w io.Writer, opts ...DownloadOption) (*FileInfo, error)
+
+	Export(ctx context.Context, appID, collectionID int, w io.Writer, opts ...ExportOption) error
+
+	GetFieldOptions(ctx context.Context, appID, collectionID int, fieldName string) ([]FieldOption, error)
+
+	Parallel(ctx context.Context, concurrency int) *QueryGroup
+
+	Import(ctx context.Context, appID, collectionID int, r io.Reader, format Format, opts ...ImportOption) (ImportResult, error)
+
+	AcquireLock(ctx context.Context, appID, collectionID, itemID, timeout int) (*ItemLock, error)
+	WithLock(ctx context.Context, appID, collectionID, itemID, timeout int, fn func(ctx context.Context) error) (err error)
+
+	RefreshLock(ctx context.Context, appID, collectionID, itemID int, lockID string, newTimeout int) (*LockResult, error)
+	UpdateItemLocked(ctx context.Context, appID, collectionID, itemID int, timeout int, data map[string]interface{}) (*Item, error)
+
+	ModifyItem(ctx context.Context, appID, collectionID, itemID int, fn func(item *Item) (map[string]interface{}, error)) error
+
+	CreateItemFromStruct(ctx context.Context, appID, collectionID int, v interface{}) (*Item, error)
+	UpdateItemFromStruct(ctx context.Context, appID, collectionID, itemID int, v interface{}) (*Response, error)
+
+	PatchItem(ctx context.Context, appID, collectionID, itemID int, data map[string]interface{}, opts ...WriteOption) (*Response, error)
+	IncrementField(ctx context.Context, appID, collectionID, itemID int, field string, n float64) (*Response, error)
+	AppendToArrayField(ctx context.Context, appID, collectionID, itemID int, field string, values ...interface{}) (*Response, error)
+	RemoveField(ctx context.Context, appID, collectionID, itemID int, field string) (*Response, error)
+
+	ParseQuery(appID, collectionID int, data []byte) (*Query, error)
+	QueryFromDefinition(appID, collectionID int, def QueryDefinition, allowedFields ...string) (*Query, error)
+
+	UploadResumable(ctx context.Context, r io.ReaderAt, size int64, filename, contentType string, opts ...UploadOption) (*UploadResult, error)
+
+	GetItemAsOf(ctx context.Context, appID, collectionID, itemID int, at time.Time, opts ...ItemOption) (*Item, error)
+	ListItemRevisions(ctx context.Context, appID, collectionID, itemID int) ([]Revision, error)
+	GetItemRevision(ctx context.Context, appID, collectionID, itemID, revisionID int) (*Revision, error)
+	ArchiveItem(ctx context.Context, appID, collectionID, itemID int) (*Response, error)
+	RestoreItem(ctx context.Context, appID, collectionID, itemID int) (*Response, error)
+
+	ResolveCollection(ctx context.Context, appSlug, collectionSlug string) (appID, collectionID int, err error)
+
+	GetCollectionStats(ctx context.Context, appID, collectionID int) (*CollectionStats, error)
+
+	CreateSubmissionToken(ctx context.Context, appID, collectionID int, opts SubmissionTokenOptions) (*SubmissionToken, error)
+	CreateUpdateToken(ctx context.Context, appID, collectionID, itemID int, opts SubmissionTokenOptions) (*SubmissionToken, error)
+	GetSubmissionTokenWithOptions(ctx context.Context, appID, collectionID int, opts SubmissionTokenOptions) (*Response, error)
+	UpdateSubmissionTokenWithOptions(ctx context.Context, appID, collectionID, itemID int, opts SubmissionTokenOptions) (*Response, error)
+
+	Subscribe(ctx context.Context, appID, collectionID int, opts ...SubscribeOption) (*Subscription, error)
+
+	ChangedSince(ctx context.Context, appID, collectionID int, since time.Time) (items []Item, checkpoint time.Time, err error)
+
+	AddTag(ctx context.Context, appID, collectionID int, itemIDs []int, field, tag string) error
+	RemoveTag(ctx context.Context, appID, collectionID int, itemIDs []int, field, tag string) error
+
+	AccessToken(ctx context.Context) (string, error)
+
+	Transaction(ctx context.Context, fn func(tx *Tx) error, opts ...TxOption) (*TxResult, error)
+
+	UploadFile(ctx context.Context, r io.Reader, filename, contentType string, opts ...UploadOption) (*UploadResult, error)
+	UploadMultipart(ctx context.Context, r io.Reader, filename, contentType string, opts ...UploadOption) (*UploadResult, error)
+
+	UpsertItem(ctx context.Context, appID, collectionID int, match, data map[string]interface{}, opts ...UpsertOption) (item *Item, created bool, err error)
+	FindOrCreateItem(ctx context.Context, appID, collectionID int, match, data map[string]interface{}) (item *Item, created bool, err error)
+
+	Me(ctx context.Context) (*Identity, error)
+	ListRoles(ctx context.Context, appID int) ([]Role, error)
+	ListMembers(ctx context.Context, appID int) ([]Member, error)
+	InviteMember(ctx context.Context, appID int, email, roleID string) (*Member, error)
+	SetMemberRole(ctx context.Context, appID, userID int, roleID string) (*Member, error)
+	RemoveMember(ctx context.Context, appID, userID int) error
+
+	ValidateItemData(ctx context.Context, appID, collectionID int, data map[string]interface{}) error
+	ValidateItemUpdate(ctx context.Context, appID, collectionID int, data map[string]interface{}) error
+}
+
+var _ CartHooksAPI = (*Client)(nil)
+
+// ItemsAPI is the item operations of Client, a subset of CartHooksAPI for
+// code that only needs these.
+type ItemsAPI interface {
+	Query(appID, collectionID int) *Query
+	GetItemByIDContext(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Item, error)
+	CreateItemContext(ctx context.Context, appID, collectionID int, data map[string]interface{}) (*Item, error)
+	UpdateItemContext(ctx context.Context, appID, collectionID, itemID int, data map[string]interface{}, opts ...WriteOption) (*Response, error)
+	DeleteItemContext(ctx context.Context, appID, collectionID, itemID int, opts ...WriteOption) (*Response, error)
+	LockItemContext(ctx context.Context, appID, collectionID, itemID, lockTimeout int, lockID, subject string) (*Response, error)
+	UnlockItemContext(ctx context.Context, appID, collectionID, itemID int, lockID string) (*Response, error)
+}
+
+var _ ItemsAPI = (*Client)(nil)
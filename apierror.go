@@ -0,0 +1,89 @@
+package carthooks
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// maxErrorBody bounds how much of a non-JSON error body APIError.Error
+// quotes.
+const maxErrorBody = 200
+
+// APIError is a request the API answered with an error: a non-2xx status, or
+// an error envelope in a successful response. Errors for statuses with their
+// own type, such as ConflictError, wrap an APIError, so errors.As finds it
+// for any failed request. A 404 matches ErrNotFound, and a 400 or 422
+// matches ErrInvalid.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Type       string
+	Key        string
+	TraceId    string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// newAPIError builds the error for a response with the given status, decoded
+// envelope and raw body.
+func newAPIError(statusCode int, result *Response, body []byte) *APIError {
+	e := &APIError{StatusCode: statusCode, TraceId: result.TraceId, Body: body}
+	if result.Error != nil {
+		e.Message, e.Type, e.Key = result.Error.Message, result.Error.Type, result.Error.Key
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("carthooks: status %d", e.StatusCode)
+	if e.Key != "" {
+		msg += " " + e.Key
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	} else if e.Key == "" && len(e.Body) > 0 && !isJSONBody(e.Body) {
+		body := e.Body
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		if utf8.Valid(body) {
+			msg += ": " + string(body)
+		}
+	}
+	if e.TraceId != "" {
+		msg += " (trace_id " + e.TraceId + ")"
+	}
+	return msg
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// Unwrap returns the error envelope as a *ResponseError, if there was one.
+func (e *APIError) Unwrap() error {
+	if e.Key == "" && e.Message == "" && e.Type == "" {
+		return nil
+	}
+	return &ResponseError{Message: e.Message, Type: e.Type, Key: e.Key}
+}
+
+func isJSONBody(body []byte) bool {
+	for _, b := range body {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		}
+		return false
+	}
+	return false
+}
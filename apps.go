@@ -0,0 +1,85 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// App is a Carthooks app, the container of collections.
+type App struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Settings holds app-wide settings such as the default locale.
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	// Metadata is free-form data stored with the app by integrations.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// CreatedAt and UpdatedAt are set by the server and ignored on writes.
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// ListApps returns the apps the access token can see.
+func (c *Client) ListApps(ctx context.Context) ([]App, error) {
+	rsp, err := c.GetContext(ctx, c.baseUrl+"/v1/apps")
+	if err != nil {
+		return nil, err
+	}
+	apps := []App{}
+	if err := rsp.Bind(&apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// GetApp returns an app.
+func (c *Client) GetApp(ctx context.Context, appID int) (*App, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d", c.baseUrl, appID)
+	return c.appRequest(ctx, http.MethodGet, urladdr, nil)
+}
+
+// CreateApp creates an app and returns it as stored.
+func (c *Client) CreateApp(ctx context.Context, app App) (*App, error) {
+	return c.appRequest(ctx, http.MethodPost, c.baseUrl+"/v1/apps", c.appBody(app))
+}
+
+// UpdateApp replaces the name, slug, description, settings and metadata of an
+// app.
+func (c *Client) UpdateApp(ctx context.Context, appID int, app App) (*App, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d", c.baseUrl, appID)
+	return c.appRequest(ctx, http.MethodPut, urladdr, c.appBody(app))
+}
+
+// appBody is the payload of an app write, without the server-set fields.
+func (c *Client) appBody(app App) requestBody {
+	data := map[string]interface{}{"name": app.Name}
+	if app.Slug != "" {
+		data["slug"] = app.Slug
+	}
+	if app.Description != "" {
+		data["description"] = app.Description
+	}
+	if app.Settings != nil {
+		data["settings"] = app.Settings
+	}
+	if app.Metadata != nil {
+		data["metadata"] = app.Metadata
+	}
+	return jsonBody(c.envelope(data))
+}
+
+func (c *Client) appRequest(ctx context.Context, method, urladdr string, body requestBody) (*App, error) {
+	rsp, err := c.do(ctx, method, urladdr, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{}
+	if err := rsp.Bind(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,172 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Attachment is one file stored in an attachment field.
+type Attachment struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mime"`
+}
+
+func (a *Attachment) UnmarshalJSON(data []byte) error {
+	type attachment Attachment
+	var raw struct {
+		attachment
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = Attachment(raw.attachment)
+	if len(raw.ID) > 0 && string(raw.ID) != "null" {
+		if err := json.Unmarshal(raw.ID, &a.ID); err != nil {
+			// Numeric IDs are kept in their decimal form.
+			a.ID = string(raw.ID)
+		}
+	}
+	return nil
+}
+
+// Attachments returns the files stored in the named attachment field. A field
+// holding a single file yields a one-element slice; an empty field yields nil.
+func (i *Item) Attachments(fieldName string) ([]Attachment, error) {
+	value, ok := i.Fields[fieldName]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, single := value.(map[string]interface{}); single {
+		var a Attachment
+		if err := json.Unmarshal(data, &a); err != nil {
+			return nil, fmt.Errorf("carthooks: field %q is not an attachment field: %w", fieldName, err)
+		}
+		return []Attachment{a}, nil
+	}
+	var files []Attachment
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("carthooks: field %q is not an attachment field: %w", fieldName, err)
+	}
+	return files, nil
+}
+
+// OpenAttachment streams the first file of the named attachment field. The
+// caller must close the returned reader.
+func (i *Item) OpenAttachment(ctx context.Context, c *Client, fieldName string) (io.ReadCloser, error) {
+	return i.OpenAttachmentAt(ctx, c, fieldName, 0)
+}
+
+// OpenAttachmentAt streams the file at index of a multi-file field.
+func (i *Item) OpenAttachmentAt(ctx context.Context, c *Client, fieldName string, index int) (io.ReadCloser, error) {
+	files, err := i.Attachments(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(files) {
+		return nil, fmt.Errorf("carthooks: field %q has no attachment at index %d", fieldName, index)
+	}
+	return c.OpenFile(ctx, files[index])
+}
+
+// OpenAttachmentNamed streams the file called name from a multi-file field.
+func (i *Item) OpenAttachmentNamed(ctx context.Context, c *Client, fieldName, name string) (io.ReadCloser, error) {
+	files, err := i.Attachments(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.Name == name {
+			return c.OpenFile(ctx, f)
+		}
+	}
+	return nil, fmt.Errorf("carthooks: field %q has no attachment named %q", fieldName, name)
+}
+
+// OpenFile streams the content of an attachment. Relative URLs are resolved
+// against the API base URL, and the access token is only sent when the file
+// is served by the API host itself.
+func (c *Client) OpenFile(ctx context.Context, a Attachment) (io.ReadCloser, error) {
+	if a.URL == "" {
+		return nil, fmt.Errorf("carthooks: attachment %s has no URL", strconv.Quote(a.ID))
+	}
+	target, err := url.Parse(a.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsAbs() {
+		base, err := url.Parse(c.baseUrl)
+		if err != nil {
+			return nil, err
+		}
+		target = base.ResolveReference(target)
+	}
+	resp, err := c.getFile(ctx, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// getFile requests a file, sending the access token only to the API host.
+// A 200 or 206 response is returned with a body that reports the exchange
+// to the observer when closed; other responses are turned into an
+// *APIError.
+func (c *Client) getFile(ctx context.Context, target *url.URL, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	var sign func(*http.Request) error
+	if c.isAPIHost(req.URL) {
+		if err := c.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		sign = c.signWith([]byte{})
+	}
+	start := c.clock.Now()
+	info := RequestInfo{Method: http.MethodGet, URL: req.URL.String(), Route: routeOf(req.URL.String()), Attempt: 1}
+	resp, err := c.send(ctx, req, sign, &info)
+	if err != nil {
+		info.Duration, info.Err = c.clock.Now().Sub(start), err
+		if err != ErrCircuitOpen {
+			c.observe(info)
+		}
+		return nil, err
+	}
+	info.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		drainAndClose(resp.Body)
+		info.Duration = c.clock.Now().Sub(start)
+		info.Err = &APIError{StatusCode: resp.StatusCode, Body: data}
+		c.observe(info)
+		return nil, info.Err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, report: func(n int64) {
+		info.Duration, info.ResponseBytes = c.clock.Now().Sub(start), n
+		c.observe(info)
+	}}
+	return resp, nil
+}
+
+func (c *Client) isAPIHost(u *url.URL) bool {
+	base, err := url.Parse(c.baseUrl)
+	return err == nil && base.Host == u.Host
+}
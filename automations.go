@@ -0,0 +1,124 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrRunFailed matches the error WaitForRun returns for a run that ended
+// unsuccessfully.
+var ErrRunFailed = errors.New("carthooks: automation run failed")
+
+// DefaultRunPollInterval is how often WaitForRun checks a run's status.
+const DefaultRunPollInterval = time.Second
+
+// Automation is a workflow defined in an app.
+type Automation struct {
+	ID          int    `json:"id"`
+	AppID       int    `json:"appId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Trigger is what starts the automation, e.g. "manual", "schedule" or
+	// "item.created".
+	Trigger string `json:"trigger"`
+	Enabled bool   `json:"enabled"`
+}
+
+// RunStatus is the state of an automation run.
+type RunStatus string
+
+const (
+	RunQueued    RunStatus = "queued"
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunCancelled RunStatus = "cancelled"
+)
+
+// AutomationRun is one execution of an automation.
+type AutomationRun struct {
+	ID           int                    `json:"id"`
+	AutomationID int                    `json:"automationId"`
+	Status       RunStatus              `json:"status"`
+	Output       map[string]interface{} `json:"output,omitempty"`
+	// Error describes why a failed run failed.
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done reports whether the run has finished, successfully or not.
+func (r *AutomationRun) Done() bool {
+	return r.Status == RunSucceeded || r.Status == RunFailed || r.Status == RunCancelled
+}
+
+// ListAutomations returns the automations of an app.
+func (c *Client) ListAutomations(ctx context.Context, appID int) ([]Automation, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/automations", c.baseUrl, appID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	automations := []Automation{}
+	if err := rsp.Bind(&automations); err != nil {
+		return nil, err
+	}
+	return automations, nil
+}
+
+// TriggerAutomation starts a run of an automation with payload as its input
+// and returns the run, usually still queued. See WaitForRun.
+func (c *Client) TriggerAutomation(ctx context.Context, appID, automationID int, payload map[string]interface{}) (*AutomationRun, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/automations/%d/runs", c.baseUrl, appID, automationID)
+	return c.runRequest(ctx, http.MethodPost, urladdr, jsonBody(c.envelope(payload)))
+}
+
+// GetAutomationRun returns the current state of a run.
+func (c *Client) GetAutomationRun(ctx context.Context, runID int) (*AutomationRun, error) {
+	urladdr := fmt.Sprintf("%s/v1/automation-runs/%d", c.baseUrl, runID)
+	return c.runRequest(ctx, http.MethodGet, urladdr, nil)
+}
+
+// WaitForRun polls a run every interval (DefaultRunPollInterval if zero or
+// less) until it finishes or ctx is done, and returns it. A run that failed
+// or was cancelled is returned together with an error matching ErrRunFailed.
+func (c *Client) WaitForRun(ctx context.Context, runID int, interval time.Duration) (*AutomationRun, error) {
+	ctx = withOperation(ctx, "WaitForRun")
+	if interval <= 0 {
+		interval = DefaultRunPollInterval
+	}
+	// Always ask the server; a cached status would never change.
+	ctx = ContextWithRequestOptions(ctx, WithNoCache())
+	for {
+		run, err := c.GetAutomationRun(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case run.Status == RunSucceeded:
+			return run, nil
+		case run.Done():
+			return run, fmt.Errorf("%w: run %d %s: %s", ErrRunFailed, run.ID, run.Status, run.Error)
+		}
+		select {
+		case <-c.clock.After(interval):
+		case <-ctx.Done():
+			return run, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) runRequest(ctx context.Context, method, urladdr string, body requestBody) (*AutomationRun, error) {
+	rsp, err := c.do(ctx, method, urladdr, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	run := &AutomationRun{}
+	if err := rsp.Bind(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
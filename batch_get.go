@@ -0,0 +1,108 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency bounds the parallel requests of helpers such as GetItems
+// when no explicit limit is given.
+const DefaultConcurrency = 8
+
+// ItemRef identifies an item across apps and collections.
+type ItemRef struct {
+	AppID        int
+	CollectionID int
+	ItemID       int
+}
+
+func (r ItemRef) String() string {
+	return fmt.Sprintf("%d/%d/%d", r.AppID, r.CollectionID, r.ItemID)
+}
+
+// ItemErrors reports which items of a multi-item read failed and why.
+type ItemErrors map[ItemRef]error
+
+func (e ItemErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for ref, err := range e {
+		keys = append(keys, ref.String()+": "+err.Error())
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("carthooks: %d item(s) failed: %s", len(e), strings.Join(keys, "; "))
+}
+
+// GetItems fetches the referenced items concurrently with GetItemByID, running
+// at most concurrency requests at a time (DefaultConcurrency if zero or less).
+// Items that were fetched are returned even when others fail; the failures
+// are reported as ItemErrors.
+func (c *Client) GetItems(ctx context.Context, refs []ItemRef, concurrency int, opts ...ItemOption) (map[ItemRef]*Item, error) {
+	ctx = withOperation(ctx, "GetItems")
+	var mu sync.Mutex
+	items := make(map[ItemRef]*Item, len(refs))
+	errs := c.forEachRef(ctx, refs, concurrency, func(ref ItemRef) error {
+		item, err := c.GetItemByIDContext(ctx, ref.AppID, ref.CollectionID, ref.ItemID, opts...)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		items[ref] = item
+		return nil
+	})
+	if len(errs) > 0 {
+		return items, errs
+	}
+	return items, nil
+}
+
+// forEachRef calls fn once per distinct ref, running at most concurrency
+// calls at a time (DefaultConcurrency if zero or less), and collects the
+// failures. Refs not started before ctx is done fail with the context error.
+// Each call first waits out any Retry-After the API asked for.
+func (c *Client) forEachRef(ctx context.Context, refs []ItemRef, concurrency int, fn func(ItemRef) error) ItemErrors {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = ItemErrors{}
+		sem  = make(chan struct{}, concurrency)
+		seen = make(map[ItemRef]bool, len(refs))
+	)
+	fail := func(ref ItemRef, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[ref] = err
+	}
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fail(ref, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(ref ItemRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := c.waitRetryAfter(ctx); err != nil {
+				fail(ref, err)
+				return
+			}
+			if err := fn(ref); err != nil {
+				fail(ref, err)
+			}
+		}(ref)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,97 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// batchFailure is one entry of the failed list of a batch write response.
+type batchFailure struct {
+	ID    int            `json:"id"`
+	Error *ResponseError `json:"error"`
+}
+
+// batchResult is the data of a batch write response.
+type batchResult struct {
+	Failed []batchFailure `json:"failed"`
+}
+
+// UpdateItems sets the given fields on all listed items in a single request.
+// Items the server could not update are reported as ItemErrors, keyed by
+// item, alongside the response.
+func (c *Client) UpdateItems(appID, collectionID int, ids []int, data map[string]interface{}) (*Response, error) {
+	return c.UpdateItemsContext(context.Background(), appID, collectionID, ids, data)
+}
+
+func (c *Client) UpdateItemsContext(ctx context.Context, appID, collectionID int, ids []int, data map[string]interface{}) (*Response, error) {
+	data, err := c.encodeFields(data)
+	if err != nil {
+		return nil, err
+	}
+	body := c.envelope(data)
+	body["ids"] = ids
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/batch-update",
+		c.baseUrl, appID, collectionID)
+	return c.batchWrite(ctx, appID, collectionID, urladdr, body)
+}
+
+// DeleteItems deletes all listed items in a single request, reporting
+// failures per item like UpdateItems.
+func (c *Client) DeleteItems(appID, collectionID int, ids []int) (*Response, error) {
+	return c.DeleteItemsContext(context.Background(), appID, collectionID, ids)
+}
+
+func (c *Client) DeleteItemsContext(ctx context.Context, appID, collectionID int, ids []int) (*Response, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/batch-delete",
+		c.baseUrl, appID, collectionID)
+	return c.batchWrite(ctx, appID, collectionID, urladdr, map[string]interface{}{"ids": ids})
+}
+
+// DeleteWhere deletes every item matching the filters of q in a single
+// request. Other query settings, such as search, sorting and paging, are
+// ignored. A query without filters is rejected rather than deleting the
+// whole collection.
+// Failures are reported per item like UpdateItems.
+func (c *Client) DeleteWhere(ctx context.Context, q *Query) (*Response, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if len(q.filters) == 0 && len(q.or) == 0 {
+		return nil, errors.New("carthooks: DeleteWhere needs at least one filter")
+	}
+	params := url.Values{}
+	addFilterParams(params, "filters", q.filters, q.or)
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/batch-delete?%s",
+		c.baseUrl, q.appID, q.collectionID, params.Encode())
+	return c.batchWrite(ctx, q.appID, q.collectionID, urladdr, map[string]interface{}{})
+}
+
+func (c *Client) batchWrite(ctx context.Context, appID, collectionID int, urladdr string, body map[string]interface{}) (*Response, error) {
+	rsp, err := c.do(ctx, http.MethodPost, urladdr, jsonBody(body), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp.Data) == 0 || string(rsp.Data) == "null" {
+		return rsp, nil
+	}
+	result := batchResult{}
+	if err := rsp.Bind(&result); err != nil {
+		return rsp, fmt.Errorf("carthooks: decoding batch result: %w", err)
+	}
+	if len(result.Failed) == 0 {
+		return rsp, nil
+	}
+	errs := ItemErrors{}
+	for _, f := range result.Failed {
+		ref := ItemRef{AppID: appID, CollectionID: collectionID, ItemID: f.ID}
+		apiErr := &APIError{StatusCode: http.StatusOK, TraceId: rsp.TraceId}
+		if f.Error != nil {
+			apiErr.Message, apiErr.Type, apiErr.Key = f.Error.Message, f.Error.Type, f.Error.Key
+		}
+		errs[ref] = apiErr
+	}
+	return rsp, errs
+}
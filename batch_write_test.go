@@ -0,0 +1,47 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestBatchWriteResult(t *testing.T) {
+	for _, tt := range []struct {
+		name, body string
+		check      func(t *testing.T, err error)
+	}{
+		{"empty", `{"data":null}`, func(t *testing.T, err error) {
+			if err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+		}},
+		{"failed items", `{"data":{"failed":[{"id":4,"error":{"key":"ERROR_ITEM_NOT_FOUND"}}]}}`, func(t *testing.T, err error) {
+			var itemErrs carthooks.ItemErrors
+			if !errors.As(err, &itemErrs) || itemErrs[carthooks.ItemRef{AppID: 1, CollectionID: 2, ItemID: 4}] == nil {
+				t.Errorf("err = %v, want an error for item 4", err)
+			}
+		}},
+		{"malformed", `{"data":{"failed":"item 4"}}`, func(t *testing.T, err error) {
+			var itemErrs carthooks.ItemErrors
+			if err == nil || errors.As(err, &itemErrs) {
+				t.Errorf("err = %v, want a decoding error", err)
+			}
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			}))
+			defer s.Close()
+			c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+			_, err := c.DeleteItemsContext(context.Background(), 1, 2, []int{3, 4})
+			tt.check(t, err)
+		})
+	}
+}
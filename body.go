@@ -0,0 +1,72 @@
+package carthooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+// requestBody is an encoded request payload together with its content type.
+type requestBody interface {
+	contentType() string
+	encode() ([]byte, error)
+}
+
+// jsonBody is the default payload encoding. It is encoded canonically, so
+// equal payloads yield identical bytes, as needed for signing and caching.
+type jsonBody map[string]any
+
+func (b jsonBody) contentType() string {
+	return "application/json"
+}
+
+func (b jsonBody) encode() ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return canonicalJSON(map[string]any(b))
+}
+
+// canonicalJSON marshals v with object keys sorted at every level. Maps are
+// sorted by encoding/json already, but values with their own MarshalJSON may
+// emit keys in any order, so the output is decoded and encoded once more.
+// Numbers are kept verbatim.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// formBody is sent as application/x-www-form-urlencoded, as expected by
+// endpoints such as OAuth token exchange.
+type formBody url.Values
+
+func (b formBody) contentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+func (b formBody) encode() ([]byte, error) {
+	return []byte(url.Values(b).Encode()), nil
+}
+
+// rawBody is sent as it is, e.g. a chunk of a file.
+type rawBody struct {
+	data []byte
+	typ  string
+}
+
+func (b rawBody) contentType() string {
+	return b.typ
+}
+
+func (b rawBody) encode() ([]byte, error) {
+	return b.data, nil
+}
@@ -0,0 +1,56 @@
+package carthooks_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// unorderedJSON marshals its map by ranging over it, so its keys come out in
+// a different order from run to run.
+type unorderedJSON map[string]int
+
+func (u unorderedJSON) MarshalJSON() ([]byte, error) {
+	var parts []string
+	for k, v := range u {
+		parts = append(parts, strconv.Quote(k)+":"+strconv.Itoa(v))
+	}
+	return []byte("{" + strings.Join(parts, ",") + "}"), nil
+}
+
+func TestRequestBodyIsCanonical(t *testing.T) {
+	var bodies [][]byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		io.WriteString(w, `{"data":{"id":1,"fields":{}}}`)
+	}))
+	defer s.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+
+	for i := 0; i < 20; i++ {
+		data := map[string]interface{}{
+			"title": "a",
+			"rows": []interface{}{
+				map[string]interface{}{"z": 1, "a": []interface{}{map[string]interface{}{"y": true, "b": nil}}},
+				unorderedJSON{"d": 4, "c": 3, "b": 2, "a": 1, "e": 5, "f": 6},
+			},
+			"amount": 12.50,
+		}
+		if _, err := c.CreateItem(1, 2, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `{"data":{"amount":12.5,"rows":[{"a":[{"b":null,"y":true}],"z":1},{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6}],"title":"a"}}`
+	for i, body := range bodies {
+		if !bytes.Equal(body, []byte(want)) {
+			t.Fatalf("body %d = %s, want %s", i, body, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("carthooks: circuit breaker is open")
+
+// CircuitBreakerSettings configures WithCircuitBreaker. Transport errors and
+// 5xx responses count as failures; everything else counts as a success.
+type CircuitBreakerSettings struct {
+	// ConsecutiveFailures trips the breaker after this many failures in a
+	// row. Zero disables the check.
+	ConsecutiveFailures int
+
+	// FailureRate trips the breaker once the share of failed requests in
+	// the current Window reaches it (0 < FailureRate <= 1), provided at
+	// least MinRequests were made. Zero disables the check.
+	FailureRate float64
+	MinRequests int
+	Window      time.Duration
+
+	// Cooldown is how long the breaker stays open before letting a probe
+	// request through. Defaults to 30 seconds.
+	Cooldown time.Duration
+
+	// Probes is how many probe requests in a row must succeed before the
+	// circuit closes again. Probes are sent one at a time. Defaults to 1.
+	Probes int
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen after
+// sustained failures. Once the cooldown elapses probe requests are let
+// through one at a time: once Probes of them succeeded the circuit closes,
+// and any failure reopens it.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) {
+		if settings.Cooldown <= 0 {
+			settings.Cooldown = 30 * time.Second
+		}
+		if settings.Probes <= 0 {
+			settings.Probes = 1
+		}
+		if settings.Window <= 0 {
+			settings.Window = time.Minute
+		}
+		c.breaker = &circuitBreaker{settings: settings}
+	}
+}
+
+// CircuitState is the state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets probe requests through one at a time.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitState returns the state of the circuit breaker, or CircuitClosed
+// without one. An open circuit whose cooldown elapsed reports CircuitOpen
+// until the next request probes it.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.state
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	probing     bool
+	probed      int
+	consecutive int
+	windowStart time.Time
+	requests    int
+	failures    int
+}
+
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.settings.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		b.probed = 0
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *circuitBreaker) record(result outcome, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+		switch result {
+		case outcomeSuccess:
+			if b.probed++; b.probed >= b.settings.Probes {
+				b.reset(now)
+				b.state = CircuitClosed
+			}
+		case outcomeFailure:
+			b.trip(now)
+		}
+		return
+	}
+	if result == outcomeIgnored || b.state != CircuitClosed {
+		return
+	}
+	if now.Sub(b.windowStart) >= b.settings.Window {
+		b.windowStart = now
+		b.requests, b.failures = 0, 0
+	}
+	b.requests++
+	if result == outcomeSuccess {
+		b.consecutive = 0
+		return
+	}
+	b.failures++
+	b.consecutive++
+	s := b.settings
+	if s.ConsecutiveFailures > 0 && b.consecutive >= s.ConsecutiveFailures {
+		b.trip(now)
+		return
+	}
+	if s.FailureRate > 0 && b.requests >= s.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= s.FailureRate {
+		b.trip(now)
+	}
+}
+
+func (b *circuitBreaker) trip(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.reset(now)
+}
+
+func (b *circuitBreaker) reset(now time.Time) {
+	b.consecutive = 0
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+}
+
+// requestOutcome classifies a request for the circuit breaker. Cancellations
+// by the caller say nothing about the API's health and are ignored.
+func requestOutcome(ctx context.Context, resp *http.Response, err error) outcome {
+	if err != nil {
+		if ctx.Err() != nil {
+			return outcomeIgnored
+		}
+		return outcomeFailure
+	}
+	if resp.StatusCode >= 500 {
+		return outcomeFailure
+	}
+	return outcomeSuccess
+}
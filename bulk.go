@@ -0,0 +1,290 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultChunkSize is the number of items a batch operation processes per
+// chunk when no size is set with WithChunkSize.
+const DefaultChunkSize = 100
+
+// ItemUpdate is one update of a batch update: the fields to set on an item.
+type ItemUpdate struct {
+	ItemID int
+	Data   map[string]interface{}
+}
+
+// BatchResult reports which items of a batch operation, or of one chunk of
+// it, succeeded and which failed.
+type BatchResult struct {
+	Succeeded []ItemRef
+	Failed    ItemErrors
+}
+
+func (r *BatchResult) merge(chunk BatchResult) {
+	r.Succeeded = append(r.Succeeded, chunk.Succeeded...)
+	for ref, err := range chunk.Failed {
+		if r.Failed == nil {
+			r.Failed = ItemErrors{}
+		}
+		r.Failed[ref] = err
+	}
+}
+
+// BatchOption customizes a batch operation such as BatchUpdateItems.
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	chunkSize   int
+	concurrency int
+	onChunk     func(chunkIndex int, result BatchResult)
+}
+
+// WithChunkSize sets how many items are processed per chunk.
+func WithChunkSize(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.chunkSize = n
+	}
+}
+
+// WithBatchConcurrency bounds the parallel requests within a chunk.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithChunkCallback registers fn to be called after each chunk completes,
+// with the chunk's zero-based index and result, e.g. to report progress or
+// checkpoint. Chunk i covers items [i*size, (i+1)*size) of the input, so an
+// interrupted operation can be resumed from the first unfinished chunk.
+func WithChunkCallback(fn func(chunkIndex int, result BatchResult)) BatchOption {
+	return func(o *batchOptions) {
+		o.onChunk = fn
+	}
+}
+
+// BatchUpdateItems applies the updates chunk by chunk through the batch
+// update endpoint, as UpdateItemsContext does: the updates of a chunk that
+// set the same data are sent in one request, and requests for differing data
+// run concurrently. Like PatchItem, an update only sets the fields it names.
+// Context cancellation is checked between chunks; if ctx is done, the result
+// covers the chunks completed so far and the context error is returned.
+// Otherwise the error is the result's Failed, if any item failed.
+func (c *Client) BatchUpdateItems(ctx context.Context, appID, collectionID int, updates []ItemUpdate, opts ...BatchOption) (BatchResult, error) {
+	ctx = withOperation(ctx, "BatchUpdateItems")
+	return runChunks(ctx, len(updates), opts, func(o batchOptions, start, end int) BatchResult {
+		return c.batchUpdateChunk(ctx, appID, collectionID, updates[start:end], o.concurrency)
+	})
+}
+
+// BatchDeleteItems deletes the items chunk by chunk, one DeleteItems request
+// per chunk, and reports the outcome per item as BatchUpdateItems does. If a
+// whole request fails, all items of its chunk are reported with that error.
+func (c *Client) BatchDeleteItems(ctx context.Context, appID, collectionID int, ids []int, opts ...BatchOption) (BatchResult, error) {
+	ctx = withOperation(ctx, "BatchDeleteItems")
+	return runChunks(ctx, len(ids), opts, func(_ batchOptions, start, end int) BatchResult {
+		chunk := ids[start:end]
+		_, err := c.DeleteItemsContext(ctx, appID, collectionID, chunk)
+		return chunkResult(appID, collectionID, chunk, err)
+	})
+}
+
+// CreateErrors maps the index of each input of BatchCreateItems that could
+// not be created to its error.
+type CreateErrors map[int]error
+
+func (e CreateErrors) Error() string {
+	indexes := make([]int, 0, len(e))
+	for i := range e {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	msgs := make([]string, len(indexes))
+	for n, i := range indexes {
+		msgs[n] = fmt.Sprintf("#%d: %v", i, e[i])
+	}
+	return fmt.Sprintf("carthooks: %d item(s) not created: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// BatchCreateItems creates an item for each entry of data, chunk by chunk,
+// running the creates of a chunk concurrently since the API has no batch
+// create. The returned items line up with data; entries that failed are nil,
+// and their errors are returned as CreateErrors keyed by index. A chunk
+// callback only sees the created items, as Succeeded.
+func (c *Client) BatchCreateItems(ctx context.Context, appID, collectionID int, data []map[string]interface{}, opts ...BatchOption) ([]*Item, error) {
+	ctx = withOperation(ctx, "BatchCreateItems")
+	items := make([]*Item, len(data))
+	errs := CreateErrors{}
+	_, err := runChunks(ctx, len(data), opts, func(o batchOptions, start, end int) BatchResult {
+		failed := c.forEachIndex(ctx, end-start, o.concurrency, func(i int) error {
+			item, err := c.CreateItemContext(ctx, appID, collectionID, data[start+i])
+			items[start+i] = item
+			return err
+		})
+		result := BatchResult{}
+		for i := start; i < end; i++ {
+			if err, ok := failed[i-start]; ok {
+				items[i] = nil
+				errs[i] = err
+			} else if items[i] != nil {
+				result.Succeeded = append(result.Succeeded, ItemRef{AppID: appID, CollectionID: collectionID, ItemID: items[i].ID})
+			}
+		}
+		return result
+	})
+	if err != nil {
+		return items, err
+	}
+	if len(errs) > 0 {
+		return items, errs
+	}
+	return items, nil
+}
+
+// runChunks calls fn for each chunk of n inputs, as set by opts, and merges
+// the results. It stops between chunks when ctx is done.
+func runChunks(ctx context.Context, n int, opts []BatchOption, fn func(o batchOptions, start, end int) BatchResult) (BatchResult, error) {
+	o := batchOptions{chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.chunkSize <= 0 {
+		o.chunkSize = DefaultChunkSize
+	}
+
+	var total BatchResult
+	for index, start := 0, 0; start < n; index, start = index+1, start+o.chunkSize {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		end := start + o.chunkSize
+		if end > n {
+			end = n
+		}
+		chunk := fn(o, start, end)
+		total.merge(chunk)
+		if o.onChunk != nil {
+			o.onChunk(index, chunk)
+		}
+	}
+	if len(total.Failed) > 0 {
+		return total, total.Failed
+	}
+	return total, nil
+}
+
+// forEachIndex calls fn for the indexes 0 to n-1 from at most concurrency
+// goroutines (DefaultConcurrency if zero or less), and returns the errors by
+// index. Indexes not yet started when ctx is done fail with its error.
+func (c *Client) forEachIndex(ctx context.Context, n, concurrency int, fn func(i int) error) map[int]error {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		next int
+		errs = map[int]error{}
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				next++
+				mu.Unlock()
+				if i >= n {
+					return
+				}
+				err := ctx.Err()
+				if err == nil {
+					err = c.waitRetryAfter(ctx)
+				}
+				if err == nil {
+					err = fn(i)
+				}
+				if err != nil {
+					mu.Lock()
+					errs[i] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func (c *Client) batchUpdateChunk(ctx context.Context, appID, collectionID int, updates []ItemUpdate, concurrency int) BatchResult {
+	// Group the items by the data set on them, one request per group.
+	var (
+		groups []ItemUpdate
+		ids    [][]int
+		byData = map[string]int{}
+	)
+	for _, u := range updates {
+		key, err := json.Marshal(u.Data)
+		if err != nil {
+			// Left to fail in a request of its own.
+			key = []byte(fmt.Sprintf("#%d", len(groups)))
+		}
+		g, ok := byData[string(key)]
+		if !ok {
+			g = len(groups)
+			byData[string(key)] = g
+			groups = append(groups, u)
+			ids = append(ids, nil)
+		}
+		ids[g] = append(ids[g], u.ItemID)
+	}
+	results := make([]BatchResult, len(groups))
+	failed := c.forEachIndex(ctx, len(groups), concurrency, func(g int) error {
+		_, err := c.UpdateItemsContext(ctx, appID, collectionID, ids[g], groups[g].Data)
+		results[g] = chunkResult(appID, collectionID, ids[g], err)
+		return nil
+	})
+	var result BatchResult
+	for g := range groups {
+		if err, ok := failed[g]; ok {
+			results[g] = chunkResult(appID, collectionID, ids[g], err)
+		}
+		result.merge(results[g])
+	}
+	return result
+}
+
+// chunkResult reports the outcome of a batch write of ids that returned err:
+// failed items as reported in ItemErrors, or all of them for any other
+// error.
+func chunkResult(appID, collectionID int, ids []int, err error) BatchResult {
+	var itemErrs ItemErrors
+	if err != nil && !errors.As(err, &itemErrs) {
+		itemErrs = ItemErrors{}
+		for _, id := range ids {
+			itemErrs[ItemRef{AppID: appID, CollectionID: collectionID, ItemID: id}] = err
+		}
+	}
+	result := BatchResult{}
+	if len(itemErrs) > 0 {
+		result.Failed = itemErrs
+	}
+	for _, id := range ids {
+		ref := ItemRef{AppID: appID, CollectionID: collectionID, ItemID: id}
+		if _, failed := itemErrs[ref]; !failed {
+			result.Succeeded = append(result.Succeeded, ref)
+		}
+	}
+	return result
+}
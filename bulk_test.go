@@ -0,0 +1,99 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestBatchUpdateItemsUsesBatchEndpoint(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	a := s.AddItem(1, 2, map[string]interface{}{"status": "open", "title": "a"})
+	b := s.AddItem(1, 2, map[string]interface{}{"status": "open", "title": "b"})
+	c := s.AddItem(1, 2, map[string]interface{}{"status": "open", "title": "c"})
+
+	result, err := s.Client().BatchUpdateItems(context.Background(), 1, 2, []carthooks.ItemUpdate{
+		{ItemID: a.ID, Data: map[string]interface{}{"status": "closed"}},
+		{ItemID: b.ID, Data: map[string]interface{}{"status": "closed"}},
+		{ItemID: c.ID, Data: map[string]interface{}{"status": "held"}},
+		{ItemID: 999, Data: map[string]interface{}{"status": "held"}},
+	})
+	var itemErrs carthooks.ItemErrors
+	if !errors.As(err, &itemErrs) || len(itemErrs) != 1 {
+		t.Fatalf("err = %v, want one item error", err)
+	}
+	if _, ok := result.Failed[carthooks.ItemRef{AppID: 1, CollectionID: 2, ItemID: 999}]; !ok {
+		t.Errorf("Failed = %v, want item 999", result.Failed)
+	}
+	if len(result.Succeeded) != 3 {
+		t.Errorf("Succeeded = %v, want 3 items", result.Succeeded)
+	}
+
+	requests := 0
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.Path, "/batch-update") {
+			t.Errorf("unexpected request %s %s", r.Method, r.Path)
+		}
+		requests++
+	}
+	if requests != 2 {
+		t.Errorf("%d batch-update requests, want one per distinct data", requests)
+	}
+	for id, want := range map[int][2]string{a.ID: {"closed", "a"}, b.ID: {"closed", "b"}, c.ID: {"held", "c"}} {
+		item, _ := s.Item(1, 2, id)
+		if item.Fields["status"] != want[0] || item.Fields["title"] != want[1] {
+			t.Errorf("item %d = %v, want status %s and title kept", id, item.Fields, want[0])
+		}
+	}
+}
+
+func TestBatchCreateItemsLinesUpWithInput(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	s.FailNext(http.StatusBadRequest, "ERROR_INVALID_FIELD", "bad title")
+
+	data := []map[string]interface{}{{"title": "first"}, {"title": "second"}, {"title": "third"}}
+	items, err := s.Client().BatchCreateItems(context.Background(), 1, 2, data, carthooks.WithBatchConcurrency(1))
+	var createErrs carthooks.CreateErrors
+	if !errors.As(err, &createErrs) || len(createErrs) != 1 || createErrs[0] == nil {
+		t.Fatalf("err = %v, want an error for input 0", err)
+	}
+	if len(items) != len(data) || items[0] != nil {
+		t.Fatalf("items = %v, want nil at 0", items)
+	}
+	for i := 1; i < len(data); i++ {
+		if items[i] == nil || items[i].Fields["title"] != data[i]["title"] {
+			t.Errorf("items[%d] = %v, want %v", i, items[i], data[i])
+		}
+	}
+}
+
+func TestBatchDeleteItemsChunks(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	var ids []int
+	for i := 0; i < 5; i++ {
+		ids = append(ids, s.AddItem(1, 2, map[string]interface{}{"n": i}).ID)
+	}
+
+	var chunks []int
+	result, err := s.Client().BatchDeleteItems(context.Background(), 1, 2, ids, carthooks.WithChunkSize(2),
+		carthooks.WithChunkCallback(func(index int, r carthooks.BatchResult) {
+			chunks = append(chunks, len(r.Succeeded))
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Succeeded) != 5 || len(s.Items(1, 2)) != 0 {
+		t.Errorf("Succeeded = %v, %d items left", result.Succeeded, len(s.Items(1, 2)))
+	}
+	if len(chunks) != 3 || chunks[0] != 2 || chunks[2] != 1 {
+		t.Errorf("chunk sizes = %v, want [2 2 1]", chunks)
+	}
+}
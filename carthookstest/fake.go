@@ -0,0 +1,34 @@
+package carthookstest
+
+import (
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// Fake implements carthooks.CartHooksAPI for unit tests: it is a Client
+// talking to its own Server, so every call, Query included, runs against the
+// in-memory items. Add fixtures and inspect the recorded calls through
+// Server.
+//
+//	fake := carthookstest.NewFake()
+//	defer fake.Close()
+//	fake.Server.AddItem(1, 2, map[string]interface{}{"status": "open"})
+//	err := codeUnderTest(ctx, fake) // takes a carthooks.CartHooksAPI
+//	calls := fake.Server.Requests()
+type Fake struct {
+	*carthooks.Client
+	Server *Server
+}
+
+var _ carthooks.CartHooksAPI = (*Fake)(nil)
+
+// NewFake starts a Fake whose client is configured by opts, as Server.Client
+// does. Close it when done.
+func NewFake(opts ...carthooks.Option) *Fake {
+	s := NewServer()
+	return &Fake{Client: s.Client(opts...), Server: s}
+}
+
+// Close stops the fake's server.
+func (f *Fake) Close() {
+	f.Server.Close()
+}
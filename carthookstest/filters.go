@@ -0,0 +1,179 @@
+package carthookstest
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// node is a parsed filter tree: nested maps of bracketed key segments with
+// string leaves, e.g. filters[status][$in][0]=open gives
+// {"status": {"$in": {"0": "open"}}}.
+type node map[string]interface{}
+
+// parseFilters collects the filters[...] parameters of query into a tree.
+func parseFilters(query url.Values) (node, error) {
+	root := node{}
+	for key, values := range query {
+		if !strings.HasPrefix(key, "filters[") || len(values) == 0 {
+			continue
+		}
+		segments := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, "filters["), "]"), "][")
+		n := root
+		for i, seg := range segments {
+			if i == len(segments)-1 {
+				n[seg] = values[0]
+				break
+			}
+			child, ok := n[seg].(node)
+			if !ok {
+				if _, leaf := n[seg]; leaf {
+					return nil, fmt.Errorf("conflicting filter %s", key)
+				}
+				child = node{}
+				n[seg] = child
+			}
+			n = child
+		}
+	}
+	return root, nil
+}
+
+// matches evaluates a filter tree against an item's fields. Conditions at one
+// level are ANDed; $or and $and hold indexed lists of subtrees.
+func matches(n node, fields map[string]interface{}) (bool, error) {
+	for key, value := range n {
+		sub, ok := value.(node)
+		if !ok {
+			return false, fmt.Errorf("filter on %s has no operator", key)
+		}
+		switch key {
+		case "$or", "$and":
+			matched := false
+			for _, branch := range list(sub) {
+				b, ok := branch.(node)
+				if !ok {
+					return false, fmt.Errorf("malformed %s group", key)
+				}
+				m, err := matches(b, fields)
+				if err != nil {
+					return false, err
+				}
+				if key == "$and" && !m {
+					return false, nil
+				}
+				matched = matched || m
+			}
+			if key == "$or" && !matched {
+				return false, nil
+			}
+		default:
+			for op, operand := range sub {
+				m, err := match(op, fields[key], operand)
+				if err != nil {
+					return false, fmt.Errorf("filter on %s: %w", key, err)
+				}
+				if !m {
+					return false, nil
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+// list returns the values of an indexed node in index order.
+func list(n node) []interface{} {
+	keys := make([]string, 0, len(n))
+	for k := range n {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = n[k]
+	}
+	return values
+}
+
+func match(op string, value, operand interface{}) (bool, error) {
+	var operands []string
+	switch o := operand.(type) {
+	case string:
+		operands = []string{o}
+	case node:
+		for _, v := range list(o) {
+			s, ok := v.(string)
+			if !ok {
+				return false, fmt.Errorf("malformed %s values", op)
+			}
+			operands = append(operands, s)
+		}
+	}
+	if len(operands) == 0 {
+		return false, fmt.Errorf("%s has no value", op)
+	}
+	str := ""
+	if value != nil {
+		str = fmt.Sprint(value)
+	}
+	first := operands[0]
+	switch op {
+	case "$eq":
+		return value != nil && compare(str, first) == 0, nil
+	case "$eqi":
+		return strings.EqualFold(str, first), nil
+	case "$ne":
+		return value == nil || compare(str, first) != 0, nil
+	case "$nei":
+		return !strings.EqualFold(str, first), nil
+	case "$lt":
+		return value != nil && compare(str, first) < 0, nil
+	case "$lte":
+		return value != nil && compare(str, first) <= 0, nil
+	case "$gt":
+		return value != nil && compare(str, first) > 0, nil
+	case "$gte":
+		return value != nil && compare(str, first) >= 0, nil
+	case "$in", "$notIn":
+		in := false
+		for _, o := range operands {
+			if value != nil && compare(str, o) == 0 {
+				in = true
+			}
+		}
+		return in == (op == "$in"), nil
+	case "$between":
+		if len(operands) != 2 {
+			return false, fmt.Errorf("$between takes two values")
+		}
+		return value != nil && compare(str, operands[0]) >= 0 && compare(str, operands[1]) <= 0, nil
+	case "$contains":
+		return strings.Contains(str, first), nil
+	case "$notContains":
+		return !strings.Contains(str, first), nil
+	case "$containsi":
+		return strings.Contains(strings.ToLower(str), strings.ToLower(first)), nil
+	case "$notContainsi":
+		return !strings.Contains(strings.ToLower(str), strings.ToLower(first)), nil
+	case "$startsWith":
+		return strings.HasPrefix(str, first), nil
+	case "$startsWithi":
+		return strings.HasPrefix(strings.ToLower(str), strings.ToLower(first)), nil
+	case "$endsWith":
+		return strings.HasSuffix(str, first), nil
+	case "$endsWithi":
+		return strings.HasSuffix(strings.ToLower(str), strings.ToLower(first)), nil
+	case "$null":
+		return (value == nil) == (first == "true"), nil
+	case "$notNull":
+		return (value != nil) == (first == "true"), nil
+	}
+	return false, fmt.Errorf("unknown operator %s", op)
+}
@@ -0,0 +1,595 @@
+// Package carthookstest provides an in-memory Carthooks API for tests.
+//
+// A Server speaks enough of the items API for code built on a
+// carthooks.Client to run against it unchanged: listing with filters, sorting,
+// field selection and pagination, reading, creating, updating, patching and
+// deleting items, batch writes, and locks. Items carry createdAt and
+// updatedAt, stamped by the server's clock on every write, and a version sent
+// as the ETag of item reads and checked against If-Match on item writes. Fixtures are added
+// with AddItem, every request is recorded, and failures can be injected with
+// FailNext:
+//
+//	srv := carthookstest.NewServer()
+//	defer srv.Close()
+//	srv.AddItem(1, 2, map[string]interface{}{"status": "open"})
+//	client := srv.Client()
+//	items, err := client.Query(1, 2).Filter("status", "$eq", "open").GetContext(ctx)
+package carthookstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// DefaultPageSize is the page size of list requests that do not set one.
+const DefaultPageSize = 25
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	// RawQuery is the query string as sent, in the client's order.
+	RawQuery string
+	Header   http.Header
+	Body     []byte
+}
+
+// Server is an in-memory Carthooks API served over HTTP. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Now is the server's clock, used for timestamps and lock expiry. It
+	// defaults to time.Now and may be replaced before the first request.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	nextID   int
+	items    map[[2]int]map[int]*record
+	requests []Request
+	failures []failure
+}
+
+type record struct {
+	fields    map[string]interface{}
+	createdAt time.Time
+	updatedAt time.Time
+	version   int
+	lockID    string
+	lockUntil time.Time
+}
+
+type failure struct {
+	status  int
+	key     string
+	message string
+}
+
+// NewServer starts a Server. Close it when done.
+func NewServer() *Server {
+	s := &Server{Now: time.Now, items: map[[2]int]map[int]*record{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the server. opts are applied after the base
+// URL, so they may override anything but it.
+func (s *Server) Client(opts ...carthooks.Option) *carthooks.Client {
+	opts = append([]carthooks.Option{carthooks.WithBaseURL(s.URL)}, opts...)
+	return carthooks.NewClient("test-token", opts...)
+}
+
+// AddItem stores an item in a collection and returns it with its new ID.
+// RFC 3339 createdAt and updatedAt values among fields set the item's
+// timestamps, which otherwise are the current time; other system fields are
+// dropped.
+func (s *Server) AddItem(appID, collectionID int, fields map[string]interface{}) carthooks.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.add(appID, collectionID, fields)
+	return s.items[[2]int{appID, collectionID}][id].item(id)
+}
+
+// Item returns the stored item, if it exists. Its Fields hold the data
+// without system fields; the timestamps are in CreatedAt and UpdatedAt.
+func (s *Server) Item(appID, collectionID, itemID int) (carthooks.Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.items[[2]int{appID, collectionID}][itemID]
+	if !ok {
+		return carthooks.Item{}, false
+	}
+	return r.item(itemID), true
+}
+
+// Items returns the items of a collection ordered by ID.
+func (s *Server) Items(appID, collectionID int) []carthooks.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []carthooks.Item
+	for _, id := range s.ids(appID, collectionID) {
+		items = append(items, s.items[[2]int{appID, collectionID}][id].item(id))
+	}
+	return items
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the next request fail with the given status and error key
+// and message instead of being handled. Calls queue up, one failure per
+// request.
+func (s *Server) FailNext(statusCode int, key, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{status: statusCode, key: key, message: message})
+}
+
+func (s *Server) add(appID, collectionID int, fields map[string]interface{}) int {
+	s.nextID++
+	k := [2]int{appID, collectionID}
+	if s.items[k] == nil {
+		s.items[k] = map[int]*record{}
+	}
+	now := s.Now().UTC()
+	rec := &record{fields: userFields(fields), createdAt: now, updatedAt: now, version: 1}
+	if t, ok := fieldTime(fields["createdAt"]); ok {
+		rec.createdAt, rec.updatedAt = t, t
+	}
+	if t, ok := fieldTime(fields["updatedAt"]); ok {
+		rec.updatedAt = t
+	}
+	s.items[k][s.nextID] = rec
+	return s.nextID
+}
+
+// touch stamps a write to rec.
+func (s *Server) touch(rec *record) {
+	rec.updatedAt = s.Now().UTC()
+	rec.version++
+}
+
+func (r *record) etag(id int) string {
+	return fmt.Sprintf(`"%d-%d"`, id, r.version)
+}
+
+func (r *record) item(id int) carthooks.Item {
+	return carthooks.Item{ID: id, Fields: copyFields(r.fields), CreatedAt: r.createdAt, UpdatedAt: r.updatedAt}
+}
+
+// apiFields returns the fields of rec as the API sends them, with the system
+// fields.
+func (r *record) apiFields() map[string]interface{} {
+	fields := copyFields(r.fields)
+	fields["createdAt"] = r.createdAt.Format(time.RFC3339Nano)
+	fields["updatedAt"] = r.updatedAt.Format(time.RFC3339Nano)
+	return fields
+}
+
+// userFields copies fields without the system fields, which only the server
+// sets.
+func userFields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if !carthooks.IsSystemField(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func fieldTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t.UTC(), err == nil
+}
+
+func (s *Server) ids(appID, collectionID int) []int {
+	var ids []int
+	for id := range s.items[[2]int{appID, collectionID}] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), RawQuery: r.URL.RawQuery, Header: r.Header.Clone(), Body: body,
+	})
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		writeError(w, f.status, f.key, f.message)
+		return
+	}
+
+	// /v1/apps/{app}/collections/{collection}/items[/...]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 6 || parts[0] != "v1" || parts[1] != "apps" || parts[3] != "collections" || parts[5] != "items" {
+		writeError(w, http.StatusNotFound, "ERROR_NOT_FOUND", "no such endpoint")
+		return
+	}
+	appID, err1 := strconv.Atoi(parts[2])
+	collectionID, err2 := strconv.Atoi(parts[4])
+	if err1 != nil || err2 != nil {
+		writeError(w, http.StatusNotFound, "ERROR_NOT_FOUND", "no such collection")
+		return
+	}
+	rest := parts[6:]
+	var payload map[string]interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeError(w, http.StatusBadRequest, "ERROR_INVALID_JSON", err.Error())
+			return
+		}
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		s.list(w, r.URL.Query(), appID, collectionID)
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		fields, _ := payload["data"].(map[string]interface{})
+		id := s.add(appID, collectionID, userFields(fields))
+		s.writeItem(w, appID, collectionID, id)
+	case len(rest) == 1 && rest[0] == "batch-update" && r.Method == http.MethodPost:
+		fields, _ := payload["data"].(map[string]interface{})
+		s.batch(w, appID, collectionID, payload["ids"], func(rec *record) {
+			for k, v := range userFields(fields) {
+				rec.fields[k] = v
+			}
+			s.touch(rec)
+		})
+	case len(rest) == 1 && rest[0] == "batch-delete" && r.Method == http.MethodPost:
+		s.batchDelete(w, r.URL.Query(), appID, collectionID, payload["ids"])
+	default:
+		itemID, err := strconv.Atoi(rest[0])
+		rec := s.items[[2]int{appID, collectionID}][itemID]
+		if err != nil || rec == nil {
+			writeError(w, http.StatusNotFound, "ERROR_ITEM_NOT_FOUND", "item not found")
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && r.Method != http.MethodGet && match != rec.etag(itemID) {
+			w.Header().Set("ETag", rec.etag(itemID))
+			writeError(w, http.StatusPreconditionFailed, "ERROR_VERSION_MISMATCH", "item has changed")
+			return
+		}
+		switch {
+		case len(rest) == 1 && r.Method == http.MethodGet:
+			s.writeItem(w, appID, collectionID, itemID)
+		case len(rest) == 1 && r.Method == http.MethodPut:
+			// PUT replaces the item's data as a whole.
+			fields, _ := payload["data"].(map[string]interface{})
+			rec.fields = userFields(fields)
+			s.touch(rec)
+			s.writeItem(w, appID, collectionID, itemID)
+		case len(rest) == 1 && r.Method == http.MethodPatch:
+			fields, _ := payload["data"].(map[string]interface{})
+			if err := patch(rec.fields, userFields(fields)); err != nil {
+				writeError(w, http.StatusBadRequest, "ERROR_INVALID_OPERATION", err.Error())
+				return
+			}
+			s.touch(rec)
+			s.writeItem(w, appID, collectionID, itemID)
+		case len(rest) == 1 && r.Method == http.MethodDelete:
+			delete(s.items[[2]int{appID, collectionID}], itemID)
+			w.WriteHeader(http.StatusNoContent)
+		case len(rest) == 2 && rest[1] == "lock" && r.Method == http.MethodPost:
+			s.lock(w, rec, payload)
+		case len(rest) == 2 && rest[1] == "unlock" && r.Method == http.MethodPost:
+			lockID, _ := payload["lockId"].(string)
+			if rec.lockID != "" && rec.lockID != lockID && s.Now().Before(rec.lockUntil) {
+				writeError(w, http.StatusConflict, "ERROR_LOCK_ID_MISMATCH", "item is locked with another lock ID")
+				return
+			}
+			rec.lockID = ""
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "ERROR_METHOD_NOT_ALLOWED", r.Method+" not allowed")
+		}
+	}
+}
+
+func (s *Server) lock(w http.ResponseWriter, rec *record, payload map[string]interface{}) {
+	lockID, _ := payload["lockId"].(string)
+	timeout, _ := payload["lockTimeout"].(float64)
+	renew, _ := payload["lockRenew"].(bool)
+	now := s.Now()
+	held := rec.lockID != "" && now.Before(rec.lockUntil)
+	switch {
+	case renew && !held:
+		writeError(w, http.StatusConflict, "ERROR_LOCK_EXPIRED", "lock has expired")
+		return
+	case renew && rec.lockID != lockID:
+		writeError(w, http.StatusConflict, "ERROR_LOCK_ID_MISMATCH", "item is locked with another lock ID")
+		return
+	case !renew && held && rec.lockID != lockID:
+		writeError(w, http.StatusLocked, "ERROR_ITEM_LOCKED", "item is locked")
+		return
+	}
+	rec.lockID = lockID
+	rec.lockUntil = now.Add(time.Duration(timeout * float64(time.Second)))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"lockId": lockID}})
+}
+
+func (s *Server) batch(w http.ResponseWriter, appID, collectionID int, rawIDs interface{}, fn func(*record)) {
+	var failed []map[string]interface{}
+	list, _ := rawIDs.([]interface{})
+	for _, raw := range list {
+		id, _ := raw.(float64)
+		rec := s.items[[2]int{appID, collectionID}][int(id)]
+		if rec == nil {
+			failed = append(failed, map[string]interface{}{
+				"id": int(id), "error": map[string]string{"key": "ERROR_ITEM_NOT_FOUND", "message": "item not found"},
+			})
+			continue
+		}
+		fn(rec)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"failed": failed}})
+}
+
+func (s *Server) batchDelete(w http.ResponseWriter, query url.Values, appID, collectionID int, rawIDs interface{}) {
+	k := [2]int{appID, collectionID}
+	if rawIDs == nil {
+		filters, err := parseFilters(query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "ERROR_INVALID_FILTER", err.Error())
+			return
+		}
+		for _, id := range s.ids(appID, collectionID) {
+			ok, err := matches(filters, s.items[k][id].apiFields())
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "ERROR_INVALID_FILTER", err.Error())
+				return
+			}
+			if ok {
+				delete(s.items[k], id)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+		return
+	}
+	list, _ := rawIDs.([]interface{})
+	deleted := map[int]bool{}
+	s.batch(w, appID, collectionID, rawIDs, func(rec *record) {})
+	for _, raw := range list {
+		id, _ := raw.(float64)
+		deleted[int(id)] = true
+	}
+	for id := range deleted {
+		delete(s.items[k], id)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, query url.Values, appID, collectionID int) {
+	filters, err := parseFilters(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ERROR_INVALID_FILTER", err.Error())
+		return
+	}
+	k := [2]int{appID, collectionID}
+	search := strings.ToLower(query.Get("_q"))
+	var items []map[string]interface{}
+	for _, id := range s.ids(appID, collectionID) {
+		fields := s.items[k][id].fields
+		withID := s.items[k][id].apiFields()
+		withID["id"] = id
+		ok, err := matches(filters, withID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "ERROR_INVALID_FILTER", err.Error())
+			return
+		}
+		if ok && (search == "" || containsTerm(fields, search)) {
+			items = append(items, withID)
+		}
+	}
+	if keys := query.Get("sort"); keys != "" {
+		sortItems(items, strings.Split(keys, ","))
+	}
+
+	page, pageSize := 1, DefaultPageSize
+	if v, err := strconv.Atoi(query.Get("pagination[page]")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(query.Get("pagination[pageSize]")); err == nil && v >= 0 {
+		pageSize = v
+	}
+	pagination := map[string]interface{}{"page": page, "pageSize": pageSize}
+	if query.Get("pagination[withCount]") != "false" {
+		pageCount := 0
+		if pageSize > 0 {
+			pageCount = (len(items) + pageSize - 1) / pageSize
+		}
+		pagination["total"], pagination["pageCount"] = len(items), pageCount
+	}
+	start := (page - 1) * pageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+
+	var selected []string
+	for i := 0; ; i++ {
+		f := query.Get("fields[" + strconv.Itoa(i) + "]")
+		if f == "" {
+			break
+		}
+		selected = append(selected, f)
+	}
+	data := []map[string]interface{}{}
+	for _, fields := range items[start:end] {
+		id := fields["id"]
+		delete(fields, "id")
+		if len(selected) > 0 {
+			projected := map[string]interface{}{}
+			for _, f := range selected {
+				if v, ok := fields[f]; ok {
+					projected[f] = v
+				}
+			}
+			fields = projected
+		}
+		data = append(data, map[string]interface{}{"id": id, "fields": fields})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": data,
+		"meta": map[string]interface{}{"pagination": pagination},
+	})
+}
+
+func (s *Server) writeItem(w http.ResponseWriter, appID, collectionID, itemID int) {
+	rec := s.items[[2]int{appID, collectionID}][itemID]
+	w.Header().Set("ETag", rec.etag(itemID))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{"id": itemID, "fields": rec.apiFields()},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// patch applies the fields of a PATCH request to fields. A value that is an
+// object with a single $inc, $push or $unset key is applied as that
+// operation; anything else replaces the field.
+func patch(fields, changes map[string]interface{}) error {
+	for k, v := range changes {
+		op, ok := v.(map[string]interface{})
+		if !ok || len(op) != 1 {
+			fields[k] = v
+			continue
+		}
+		switch {
+		case op["$inc"] != nil:
+			n, ok := op["$inc"].(float64)
+			cur := 0.0
+			if fields[k] != nil {
+				var err error
+				cur, err = strconv.ParseFloat(fmt.Sprint(fields[k]), 64)
+				ok = ok && err == nil
+			}
+			if !ok {
+				return fmt.Errorf("cannot increment field %s", k)
+			}
+			fields[k] = cur + n
+		case op["$push"] != nil:
+			values, ok := op["$push"].([]interface{})
+			cur, isList := fields[k].([]interface{})
+			if !ok || (fields[k] != nil && !isList) {
+				return fmt.Errorf("cannot append to field %s", k)
+			}
+			fields[k] = append(cur, values...)
+		case op["$unset"] != nil:
+			delete(fields, k)
+		default:
+			fields[k] = v
+		}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, key, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"key": key, "message": message},
+	})
+}
+
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		out[k] = v
+	}
+	return out
+}
+
+func containsTerm(fields map[string]interface{}, term string) bool {
+	for _, v := range fields {
+		if s, ok := v.(string); ok && strings.Contains(strings.ToLower(s), term) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortItems(items []map[string]interface{}, keys []string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			parts := strings.Split(key, ":")
+			c := compare(items[i][parts[0]], items[j][parts[0]])
+			if c == 0 {
+				continue
+			}
+			if len(parts) > 1 && parts[1] == "desc" {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compare orders two field values numerically if both are numbers, and by
+// their string form otherwise. Missing values sort first.
+func compare(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	at, aerr := time.Parse(time.RFC3339Nano, as)
+	bt, berr := time.Parse(time.RFC3339Nano, bs)
+	if aerr == nil && berr == nil {
+		switch {
+		case at.Before(bt):
+			return -1
+		case at.After(bt):
+			return 1
+		}
+		return 0
+	}
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	if aerr == nil && berr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(as, bs)
+}
@@ -0,0 +1,205 @@
+package carthookstest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+// clock is a settable time source for Server.Now.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func TestServerStampsTimestamps(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	clk := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s.Now = clk.Now
+	c := s.Client()
+	ctx := context.Background()
+
+	created, err := c.CreateItemContext(ctx, 1, 2, map[string]interface{}{"title": "a", "updatedAt": "1999-01-01T00:00:00Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.CreatedAt.Equal(clk.now) || !created.UpdatedAt.Equal(clk.now) {
+		t.Errorf("created at %v, updated at %v; want both %v", created.CreatedAt, created.UpdatedAt, clk.now)
+	}
+
+	clk.now = clk.now.Add(time.Hour)
+	if _, err := c.PatchItem(ctx, 1, 2, created.ID, map[string]interface{}{"title": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetItemByIDContext(ctx, 1, 2, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(clk.now.Add(-time.Hour)) || !got.UpdatedAt.Equal(clk.now) {
+		t.Errorf("created at %v, updated at %v after the patch", got.CreatedAt, got.UpdatedAt)
+	}
+	stored, _ := s.Item(1, 2, created.ID)
+	if want := map[string]interface{}{"title": "b"}; !reflect.DeepEqual(stored.Fields, want) {
+		t.Errorf("stored fields %v, want %v", stored.Fields, want)
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := &clock{now: start}
+	s.Now = clk.Now
+	old := s.AddItem(1, 2, map[string]interface{}{"title": "old"})
+	clk.now = start.Add(time.Minute)
+	fresh := s.AddItem(1, 2, map[string]interface{}{"title": "fresh"})
+	// Sub-second times sort after whole seconds, not before.
+	clk.now = start.Add(2*time.Minute + 500*time.Millisecond)
+	c := s.Client()
+	ctx := context.Background()
+	if _, err := c.PatchItem(ctx, 1, 2, old.ID, map[string]interface{}{"title": "touched"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items, checkpoint, err := c.ChangedSince(ctx, 1, 2, start.Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if want := []int{fresh.ID, old.ID}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got items %v, want %v in update order", ids, want)
+	}
+	if !checkpoint.Equal(clk.now) {
+		t.Errorf("got checkpoint %v, want %v", checkpoint, clk.now)
+	}
+}
+
+func TestAddItemTimestamps(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a", "createdAt": "2026-01-01T00:00:00Z", "updatedAt": "2026-01-02T00:00:00Z"})
+	if item.CreatedAt != time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) || item.UpdatedAt != time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("got created at %v, updated at %v", item.CreatedAt, item.UpdatedAt)
+	}
+	if want := map[string]interface{}{"title": "a"}; !reflect.DeepEqual(item.Fields, want) {
+		t.Errorf("got fields %v, want %v", item.Fields, want)
+	}
+}
+
+func TestServerQuery(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	for _, n := range []int{5, 1, 4, 2, 3} {
+		s.AddItem(1, 2, map[string]interface{}{"n": n, "odd": n%2 == 1})
+	}
+	items, err := s.Client().Query(1, 2).
+		Where("odd", carthooks.OpEq, true).
+		OrderBy("n", carthooks.Desc).
+		Limit(2).
+		GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ns []float64
+	for _, item := range items {
+		ns = append(ns, item.Fields["n"].(float64))
+	}
+	if want := []float64{5, 3, 1}; !reflect.DeepEqual(ns, want) {
+		t.Errorf("got %v, want %v", ns, want)
+	}
+}
+
+func TestServerFailNext(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	s.FailNext(http.StatusServiceUnavailable, "ERROR_UNAVAILABLE", "down")
+	c := s.Client()
+	ctx := context.Background()
+
+	_, err := c.GetItemByIDContext(ctx, 1, 2, item.ID)
+	var apiErr *carthooks.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Key != "ERROR_UNAVAILABLE" {
+		t.Errorf("got error %v, want the injected 503", err)
+	}
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, item.ID); err != nil {
+		t.Errorf("second request failed: %v", err)
+	}
+}
+
+// closeItems is code under test that depends on the API interface only.
+func closeItems(ctx context.Context, api carthooks.CartHooksAPI, appID, collectionID int) (int, error) {
+	items, err := api.Query(appID, collectionID).Where("status", carthooks.OpEq, "open").GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		if _, err := api.PatchItem(ctx, appID, collectionID, item.ID, map[string]interface{}{"status": "closed"}); err != nil {
+			return 0, err
+		}
+	}
+	return len(items), nil
+}
+
+func TestFake(t *testing.T) {
+	fake := carthookstest.NewFake()
+	defer fake.Close()
+	open := fake.Server.AddItem(1, 2, map[string]interface{}{"status": "open", "title": "a"})
+	fake.Server.AddItem(1, 2, map[string]interface{}{"status": "done", "title": "b"})
+
+	n, err := closeItems(context.Background(), fake, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("closed %d items, want 1", n)
+	}
+	got, _ := fake.Server.Item(1, 2, open.ID)
+	if want := map[string]interface{}{"status": "closed", "title": "a"}; !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("got %v, want %v", got.Fields, want)
+	}
+	var methods []string
+	for _, r := range fake.Server.Requests() {
+		methods = append(methods, r.Method)
+	}
+	if want := []string{http.MethodGet, http.MethodPatch}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("got calls %v, want %v", methods, want)
+	}
+}
+
+func TestServerIfMatch(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	c := s.Client()
+	ctx := context.Background()
+
+	read, err := c.GetItemByIDContext(ctx, 1, 2, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.ETag == "" {
+		t.Fatal("read has no ETag")
+	}
+	if _, err := c.PatchItem(ctx, 1, 2, item.ID, map[string]interface{}{"title": "b"}, carthooks.IfMatch(read.ETag)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.PatchItem(ctx, 1, 2, item.ID, map[string]interface{}{"title": "c"}, carthooks.IfMatch(read.ETag))
+	if !errors.Is(err, carthooks.ErrConflict) {
+		t.Errorf("err = %v for a stale version, want ErrConflict", err)
+	}
+	if got, _ := s.Item(1, 2, item.ID); got.Fields["title"] != "b" {
+		t.Errorf("title = %v, want b", got.Fields["title"])
+	}
+}
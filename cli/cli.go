@@ -0,0 +1,273 @@
+// Package cli implements the carthooks command, a scriptable interface to the
+// Carthooks API built on the SDK:
+//
+//	carthooks profile set prod -base-url https://api.carthooks.com
+//	carthooks profile set ci -client-id app -client-secret < secret.txt
+//	carthooks token set -profile prod < token.txt
+//	carthooks query 12 34 -filter 'status=open' -filter 'amount>=100' -o csv
+//	carthooks create 12 34 -data '{"title":"Hello"}'
+//	carthooks export 12 34 -format csv -out items.csv
+//
+// Credentials and endpoints are kept in named profiles in a config file; see
+// Config. The CARTHOOKS_TOKEN environment variable overrides the token of the
+// selected profile.
+//
+// The package exists so the command can be embedded or extended; App.Run
+// runs it with the given arguments and streams.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// ErrUsage is matched by the errors Run returns for invalid arguments.
+var ErrUsage = errors.New("usage")
+
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func (e *usageError) Is(target error) bool {
+	return target == ErrUsage
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// App is the carthooks command.
+type App struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// ConfigPath is the config file, DefaultConfigPath if empty. The
+	// -config flag overrides it.
+	ConfigPath string
+	// Options are passed to every client the command creates, after those
+	// derived from the profile.
+	Options []carthooks.Option
+
+	profile string
+	config  *Config
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(a *App, ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"query", "<app> <collection>", "list the items matching filters", (*App).query},
+		{"get", "<app> <collection> <item>", "print one item", (*App).get},
+		{"create", "<app> <collection>", "create an item from JSON", (*App).create},
+		{"update", "<app> <collection> <item>", "set fields of an item from JSON", (*App).update},
+		{"delete", "<app> <collection> <item>...", "delete items", (*App).delete},
+		{"export", "<app> <collection>", "write items as JSON lines or CSV", (*App).export},
+		{"import", "<app> <collection> [file]", "create items from JSON lines or CSV", (*App).importItems},
+		{"token", "set|print|clear", "manage the access token of a profile", (*App).token},
+		{"profile", "list|set|use|delete", "manage profiles", (*App).profiles},
+	}
+}
+
+// Run runs the command line args, without the program name. Invalid
+// arguments are reported as errors matching ErrUsage, after the usage of the
+// command is written to Stderr; -h returns flag.ErrHelp.
+func (a *App) Run(ctx context.Context, args []string) error {
+	if a.Stdin == nil {
+		a.Stdin = os.Stdin
+	}
+	if a.Stdout == nil {
+		a.Stdout = os.Stdout
+	}
+	if a.Stderr == nil {
+		a.Stderr = os.Stderr
+	}
+	fs := flag.NewFlagSet("carthooks", flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	fs.StringVar(&a.profile, "profile", "", "profile to use (default $CARTHOOKS_PROFILE or the config's default)")
+	fs.StringVar(&a.ConfigPath, "config", a.ConfigPath, "config file (default $CARTHOOKS_CONFIG or the user config directory)")
+	fs.Usage = func() {
+		fmt.Fprintf(a.Stderr, "usage: carthooks [-profile name] [-config file] <command> [flags] [args]\n\ncommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(a.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+		}
+		fmt.Fprintf(a.Stderr, "\nRun carthooks <command> -h for the flags of a command.\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return usageErrorf("no command given")
+	}
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(a, ctx, fs.Args()[1:])
+		}
+	}
+	fs.Usage()
+	return usageErrorf("unknown command %q", name)
+}
+
+// flags returns the flag set of a command.
+func (a *App) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	fs.StringVar(&a.profile, "profile", a.profile, "profile to use")
+	var usage string
+	for _, cmd := range commands {
+		if cmd.name == name {
+			usage = cmd.args
+		}
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(a.Stderr, "usage: carthooks %s [flags] %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of a command, which may come before, between or
+// after its arguments, and returns the arguments. It checks that there are
+// between min and max of them; max < 0 means any number.
+func parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, flagError(err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		if args[0] == "-" || !strings.HasPrefix(args[0], "-") {
+			rest = append(rest, args[0])
+			args = args[1:]
+			continue
+		}
+		// Parse stopped after "--": the rest are arguments.
+		rest = append(rest, args...)
+		break
+	}
+	if len(rest) < min || (max >= 0 && len(rest) > max) {
+		fs.Usage()
+		return nil, usageErrorf("%s: wrong number of arguments", fs.Name())
+	}
+	return rest, nil
+}
+
+// flagError makes an error of flag parsing a usage error, except for -h.
+func flagError(err error) error {
+	if err == flag.ErrHelp {
+		return err
+	}
+	return &usageError{msg: err.Error()}
+}
+
+// ids parses numeric arguments.
+func ids(args ...string) ([]int, error) {
+	out := make([]int, len(args))
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return nil, usageErrorf("%q is not an ID", arg)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// loadConfig reads the config file once.
+func (a *App) loadConfig() (*Config, string, error) {
+	path := a.ConfigPath
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigPath(); err != nil {
+			return nil, "", err
+		}
+	}
+	if a.config == nil {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return nil, "", err
+		}
+		a.config = cfg
+	}
+	return a.config, path, nil
+}
+
+// client creates a client for the selected profile.
+func (a *App) client() (*carthooks.Client, error) {
+	cfg, _, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.profileName(a.profile)
+	p := cfg.Profiles[name]
+	if p == nil {
+		if a.profile != "" {
+			return nil, fmt.Errorf("no profile %q", name)
+		}
+		p = &Profile{}
+	}
+	opts := []carthooks.Option{carthooks.WithUserAgent("carthooks-cli")}
+	if p.BaseURL != "" {
+		opts = append(opts, carthooks.WithBaseURL(p.BaseURL))
+	}
+	token := p.Token
+	if env := os.Getenv("CARTHOOKS_TOKEN"); env != "" {
+		token = env
+	} else if p.ClientID != "" {
+		opts = append(opts, carthooks.WithClientCredentials(p.ClientID, p.ClientSecret, p.Scopes...))
+	}
+	if token == "" && p.ClientID == "" {
+		return nil, fmt.Errorf("no credentials for profile %q; run carthooks token set or set CARTHOOKS_TOKEN", name)
+	}
+	c := carthooks.NewClient(token, append(opts, a.Options...)...)
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// listFlag collects the values of a repeated flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty elements.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
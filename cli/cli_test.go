@@ -0,0 +1,145 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+	"github.com/carthooks/carthooks-sdk-golang/cli"
+)
+
+// newApp returns an App whose default profile talks to s, and its stdout.
+func newApp(t *testing.T, s *carthookstest.Server, stdin string) (*cli.App, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("CARTHOOKS_TOKEN", "")
+	t.Setenv("CARTHOOKS_PROFILE", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &cli.Config{Profiles: map[string]*cli.Profile{"default": {Token: "test-token", BaseURL: s.URL}}}
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	return &cli.App{Stdin: strings.NewReader(stdin), Stdout: &stdout, Stderr: &bytes.Buffer{}, ConfigPath: path}, &stdout
+}
+
+func TestProfileSetReadsSecretFromStdin(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	app, stdout := newApp(t, s, "s3cret\n")
+
+	err := app.Run(context.Background(), []string{"profile", "set", "ci", "-client-id", "app", "-client-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := cli.LoadConfig(app.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.Profiles["ci"]
+	if p == nil || p.ClientID != "app" || p.ClientSecret != "s3cret" {
+		t.Errorf("profile = %+v, want client app with the secret from stdin", p)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+}
+
+func TestUpdateKeepsOtherFields(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "keep me", "status": "open"})
+	app, _ := newApp(t, s, "")
+
+	err := app.Run(context.Background(), []string{"update", "1", "2", fmt.Sprint(item.ID), "-data", `{"status":"closed"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Item(1, 2, item.ID)
+	if got.Fields["status"] != "closed" || got.Fields["title"] != "keep me" {
+		t.Errorf("fields = %v, want status closed and title kept", got.Fields)
+	}
+	if r := s.Requests(); len(r) != 1 || r[0].Method != http.MethodPatch {
+		t.Errorf("requests = %+v, want one PATCH", r)
+	}
+}
+
+func TestQueryCSV(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	a := s.AddItem(1, 2, map[string]interface{}{"title": "a", "n": 1, "tags": []interface{}{"x", "y"}})
+	b := s.AddItem(1, 2, map[string]interface{}{"title": "b", "n": 2.5})
+	app, stdout := newApp(t, s, "")
+
+	if err := app.Run(context.Background(), []string{"query", "1", "2", "-o", "csv", "-fields", "title,n,tags", "-sort", "title"}); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("id,title,n,tags\n%d,a,1,\"[\"\"x\"\",\"\"y\"\"]\"\n%d,b,2.5,\n", a.ID, b.ID)
+	if stdout.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", stdout, want)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	for _, title := range []string{"a", "b", "c"} {
+		s.AddItem(1, 2, map[string]interface{}{"title": title, "secret": "x"})
+	}
+	app, _ := newApp(t, s, "")
+	file := filepath.Join(t.TempDir(), "items.csv")
+	ctx := context.Background()
+
+	if err := app.Run(ctx, []string{"export", "1", "2", "-columns", "title", "-out", file}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "id,title\n") {
+		t.Fatalf("export =\n%s\nwant only the id and title columns", data)
+	}
+	if err := app.Run(ctx, []string{"import", "1", "3", file}); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, item := range s.Items(1, 3) {
+		if _, ok := item.Fields["secret"]; ok {
+			t.Errorf("imported item %d has an unexported field", item.ID)
+		}
+		titles = append(titles, fmt.Sprint(item.Fields["title"]))
+	}
+	sort.Strings(titles)
+	if strings.Join(titles, ",") != "a,b,c" {
+		t.Errorf("imported titles = %v, want a, b and c", titles)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"get", "1", "x", "3"},
+		{"get", "1", "2"},
+		{"query", "1", "2", "-filter", "status"},
+		{"import", "1", "2", "-types", "n=decimal"},
+	} {
+		app, _ := newApp(t, s, "")
+		if err := app.Run(context.Background(), args); !errors.Is(err, cli.ErrUsage) {
+			t.Errorf("Run(%q) = %v, want a usage error", args, err)
+		}
+	}
+	if len(s.Requests()) != 0 {
+		t.Errorf("%d requests sent for invalid arguments", len(s.Requests()))
+	}
+}
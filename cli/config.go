@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Profile holds the credentials and endpoint of one account or environment.
+type Profile struct {
+	// Token is a static access token.
+	Token string `json:"token,omitempty"`
+	// ClientID and ClientSecret are OAuth2 client credentials, used instead
+	// of Token when set.
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	// BaseURL is the API endpoint; the SDK's default when empty.
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// Config is the CLI's config file, holding named profiles.
+type Config struct {
+	// Default is the profile used when none is selected.
+	Default  string              `json:"default,omitempty"`
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// DefaultConfigPath returns $CARTHOOKS_CONFIG, or else carthooks/config.json
+// in the user's config directory.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv("CARTHOOKS_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "carthooks", "config.json"), nil
+}
+
+// LoadConfig reads the config file at path. A missing file is an empty
+// config.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// Save writes the config to path, readable only by its owner since it holds
+// secrets.
+func (cfg *Config) Save(path string) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// profileName returns the name of the selected profile: name if set, else
+// $CARTHOOKS_PROFILE, the config's default or "default".
+func (cfg *Config) profileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name = os.Getenv("CARTHOOKS_PROFILE"); name != "" {
+		return name
+	}
+	if cfg.Default != "" {
+		return cfg.Default
+	}
+	return "default"
+}
+
+// names returns the profile names in order.
+func (cfg *Config) names() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// queryFlags are the flags that select and order items.
+type queryFlags struct {
+	filters listFlag
+	sort    string
+	search  string
+}
+
+func (f *queryFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.filters, "filter", "condition such as status=open, amount>=100, title~=draft or tags[$in]=a,b (repeatable)")
+	fs.StringVar(&f.sort, "sort", "", "comma-separated sort keys such as createdAt:desc")
+	fs.StringVar(&f.search, "search", "", "full-text search term")
+}
+
+func (f *queryFlags) apply(q *carthooks.Query) error {
+	for _, s := range f.filters {
+		field, op, value, err := parseFilter(s)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case carthooks.OpIn, carthooks.OpNotIn, carthooks.OpBetween:
+			q.FilterValues(field, op, strings.Split(value, ","))
+		default:
+			q.Filter(field, op, value)
+		}
+	}
+	if keys := splitList(f.sort); len(keys) > 0 {
+		q.Sort(keys...)
+	}
+	if f.search != "" {
+		q.FullText(f.search)
+	}
+	return nil
+}
+
+// filterOperators maps the shorthand operators of -filter to API operators,
+// two-character ones first.
+var filterOperators = []struct{ short, op string }{
+	{"!=", carthooks.OpNe},
+	{">=", carthooks.OpGte},
+	{"<=", carthooks.OpLte},
+	{"~=", carthooks.OpContainsi},
+	{"=", carthooks.OpEq},
+	{">", carthooks.OpGt},
+	{"<", carthooks.OpLt},
+}
+
+// parseFilter parses field[$op]=value or field<shorthand>value.
+func parseFilter(s string) (field, op, value string, err error) {
+	if open := strings.Index(s, "[$"); open > 0 {
+		if end := strings.Index(s[open:], "]="); end > 0 {
+			return s[:open], s[open+1 : open+end], s[open+end+2:], nil
+		}
+	}
+	at := strings.IndexAny(s, "=!<>~")
+	if at <= 0 {
+		return "", "", "", usageErrorf("filter %q has no operator", s)
+	}
+	for _, o := range filterOperators {
+		if strings.HasPrefix(s[at:], o.short) {
+			return s[:at], o.op, s[at+len(o.short):], nil
+		}
+	}
+	return "", "", "", usageErrorf("filter %q has no operator", s)
+}
+
+func (a *App) query(ctx context.Context, args []string) error {
+	fs := a.flags("query")
+	var qf queryFlags
+	qf.register(fs)
+	limit := fs.Int("limit", 20, "items per page")
+	page := fs.Int("page", 0, "page to fetch, from 1")
+	all := fs.Bool("all", false, "fetch every page")
+	fields := fs.String("fields", "", "comma-separated fields to fetch and show")
+	output := fs.String("o", OutputTable, "output format: table, json or csv")
+	args, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	q := c.Query(n[0], n[1]).Limit(*limit)
+	if err := qf.apply(q); err != nil {
+		return err
+	}
+	columns := splitList(*fields)
+	if len(columns) > 0 {
+		q.Select(columns...)
+	}
+	var items []carthooks.Item
+	if *all {
+		items, err = q.WithoutCount().GetAll(ctx)
+	} else {
+		if *page > 0 {
+			q.Page(*page)
+		}
+		items, err = q.GetContext(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return writeItems(a.Stdout, *output, items, columns, false)
+}
+
+func (a *App) get(ctx context.Context, args []string) error {
+	fs := a.flags("get")
+	fields := fs.String("fields", "", "comma-separated fields to fetch and show")
+	output := fs.String("o", OutputJSON, "output format: table, json or csv")
+	args, err := parse(fs, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	columns := splitList(*fields)
+	var opts []carthooks.ItemOption
+	if len(columns) > 0 {
+		opts = append(opts, carthooks.WithFields(columns...))
+	}
+	item, err := c.GetItemByIDContext(ctx, n[0], n[1], n[2], opts...)
+	if err != nil {
+		return err
+	}
+	return writeItems(a.Stdout, *output, []carthooks.Item{*item}, columns, true)
+}
+
+func (a *App) create(ctx context.Context, args []string) error {
+	fs := a.flags("create")
+	data := fs.String("data", "", `fields as a JSON object, or "-" to read them from stdin (the default)`)
+	output := fs.String("o", OutputJSON, "output format: table, json or csv")
+	args, err := parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	fields, err := a.readFields(*data)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	item, err := c.CreateItemContext(ctx, n[0], n[1], fields)
+	if err != nil {
+		return err
+	}
+	return writeItems(a.Stdout, *output, []carthooks.Item{*item}, nil, true)
+}
+
+func (a *App) update(ctx context.Context, args []string) error {
+	fs := a.flags("update")
+	data := fs.String("data", "", `fields to set as a JSON object, or "-" to read them from stdin (the default)`)
+	ifMatch := fs.String("if-match", "", "only update if the item's ETag is still this one")
+	args, err := parse(fs, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	fields, err := a.readFields(*data)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var opts []carthooks.WriteOption
+	if *ifMatch != "" {
+		opts = append(opts, carthooks.IfMatch(*ifMatch))
+	}
+	_, err = c.PatchItem(ctx, n[0], n[1], n[2], fields, opts...)
+	return err
+}
+
+func (a *App) delete(ctx context.Context, args []string) error {
+	fs := a.flags("delete")
+	args, err := parse(fs, args, 3, -1)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	for _, itemID := range n[2:] {
+		if _, err := c.DeleteItemContext(ctx, n[0], n[1], itemID); err != nil {
+			return fmt.Errorf("deleting item %d: %w", itemID, err)
+		}
+	}
+	return nil
+}
+
+// readFields decodes a JSON object from data, or from stdin if data is
+// empty or "-". Numbers are kept as written.
+func (a *App) readFields(data string) (map[string]interface{}, error) {
+	var r io.Reader = strings.NewReader(data)
+	if data == "" || data == "-" {
+		r = a.Stdin
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("reading fields: %w", err)
+	}
+	if fields == nil {
+		return nil, usageErrorf("fields must be a JSON object")
+	}
+	return fields, nil
+}
+
+// openInput opens the named file, or stdin for "" or "-".
+func (a *App) openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(a.Stdin), nil
+	}
+	return os.Open(name)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// Output formats of the -o flag.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+)
+
+// maxCellWidth is how many characters of a value a table shows.
+const maxCellWidth = 40
+
+type jsonItem struct {
+	ID     int                    `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// writeItems writes items in format. columns are the fields shown in tables
+// and CSV, all of them in sorted order if empty; values are written as Export
+// writes them. single writes JSON as one object instead of a list.
+func writeItems(w io.Writer, format string, items []carthooks.Item, columns []string, single bool) error {
+	if len(columns) == 0 {
+		columns = carthooks.FieldNames(items)
+	}
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if single && len(items) == 1 {
+			return enc.Encode(jsonItem{ID: items[0].ID, Fields: items[0].Fields})
+		}
+		out := make([]jsonItem, len(items))
+		for i, item := range items {
+			out[i] = jsonItem{ID: item.ID, Fields: item.Fields}
+		}
+		return enc.Encode(out)
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(append([]string{"id"}, columns...)); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := cw.Write(itemRow(item, columns, 0)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(append([]string{"id"}, columns...), "\t")))
+		for _, item := range items {
+			fmt.Fprintln(tw, strings.Join(itemRow(item, columns, maxCellWidth), "\t"))
+		}
+		return tw.Flush()
+	}
+	return usageErrorf("unknown output format %q; use table, json or csv", format)
+}
+
+// itemRow formats the ID and columns of item, cutting values to width
+// characters unless width is zero.
+func itemRow(item carthooks.Item, columns []string, width int) []string {
+	row := make([]string, 0, len(columns)+1)
+	row = append(row, strconv.Itoa(item.ID))
+	for _, name := range columns {
+		s, err := carthooks.FormatValue(item.Fields[name])
+		if err != nil {
+			s = fmt.Sprint(item.Fields[name])
+		}
+		if width > 0 {
+			s = truncate(strings.Join(strings.Fields(s), " "), width)
+		}
+		row = append(row, s)
+	}
+	return row
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+func (a *App) token(ctx context.Context, args []string) error {
+	fs := a.flags("token")
+	args, err := parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "set":
+		var token string
+		if len(args) == 2 {
+			token = strings.TrimSpace(args[1])
+		} else if token, err = a.readSecret("token"); err != nil {
+			return err
+		}
+		if token == "" {
+			return usageErrorf("empty token")
+		}
+		return a.editProfile(func(p *Profile) { p.Token = token })
+	case "clear":
+		if len(args) != 1 {
+			break
+		}
+		return a.editProfile(func(p *Profile) { p.Token = "" })
+	case "print":
+		if len(args) != 1 {
+			break
+		}
+		c, err := a.client()
+		if err != nil {
+			return err
+		}
+		token, err := c.AccessToken(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.Stdout, token)
+		return nil
+	}
+	fs.Usage()
+	return usageErrorf("token: use set [token], print or clear")
+}
+
+// readSecret reads the first line of stdin, so that secrets stay out of the
+// shell history and the process list.
+func (a *App) readSecret(what string) (string, error) {
+	line, err := bufio.NewReader(a.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading %s: %w", what, err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// editProfile changes the selected profile, creating it if needed, and saves
+// the config.
+func (a *App) editProfile(fn func(p *Profile)) error {
+	cfg, path, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	name := cfg.profileName(a.profile)
+	p := cfg.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+		cfg.Profiles[name] = p
+	}
+	fn(p)
+	return cfg.Save(path)
+}
+
+func (a *App) profiles(ctx context.Context, args []string) error {
+	fs := a.flags("profile")
+	baseURL := fs.String("base-url", "", "API endpoint (set)")
+	clientID := fs.String("client-id", "", "OAuth2 client ID (set)")
+	clientSecret := fs.Bool("client-secret", false, "read the OAuth2 client secret from stdin (set)")
+	scopes := fs.String("scopes", "", "comma-separated OAuth2 scopes (set)")
+	makeDefault := fs.Bool("default", false, "also make the profile the default (set)")
+	args, err := parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	cfg, path, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(args) == 2 {
+		name = args[1]
+	}
+	switch {
+	case args[0] == "list" && name == "":
+		tw := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tBASE URL\tAUTH\tDEFAULT")
+		for _, name := range cfg.names() {
+			p := cfg.Profiles[name]
+			auth := "-"
+			switch {
+			case p.ClientID != "":
+				auth = "client credentials"
+			case p.Token != "":
+				auth = "token"
+			}
+			def := ""
+			if name == cfg.profileName("") {
+				def = "*"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, p.BaseURL, auth, def)
+		}
+		return tw.Flush()
+	case args[0] == "set" && name != "":
+		secret := ""
+		if *clientSecret {
+			if secret, err = a.readSecret("client secret"); err != nil {
+				return err
+			}
+			if secret == "" {
+				return usageErrorf("empty client secret")
+			}
+		}
+		p := cfg.Profiles[name]
+		if p == nil {
+			p = &Profile{}
+			cfg.Profiles[name] = p
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "base-url":
+				p.BaseURL = *baseURL
+			case "client-id":
+				p.ClientID = *clientID
+			case "client-secret":
+				p.ClientSecret = secret
+			case "scopes":
+				p.Scopes = splitList(*scopes)
+			}
+		})
+		if *makeDefault || len(cfg.Profiles) == 1 {
+			cfg.Default = name
+		}
+		return cfg.Save(path)
+	case args[0] == "use" && name != "":
+		if cfg.Profiles[name] == nil {
+			return fmt.Errorf("no profile %q", name)
+		}
+		cfg.Default = name
+		return cfg.Save(path)
+	case args[0] == "delete" && name != "":
+		if cfg.Profiles[name] == nil {
+			return fmt.Errorf("no profile %q", name)
+		}
+		delete(cfg.Profiles, name)
+		if cfg.Default == name {
+			cfg.Default = ""
+		}
+		return cfg.Save(path)
+	}
+	fs.Usage()
+	return usageErrorf("profile: use list, set <name>, use <name> or delete <name>")
+}
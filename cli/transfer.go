@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// columnTypes are the names of import column types for -types.
+var columnTypes = map[string]carthooks.ColumnType{
+	"string": carthooks.ColumnString,
+	"number": carthooks.ColumnNumber,
+	"bool":   carthooks.ColumnBool,
+	"list":   carthooks.ColumnList,
+	"json":   carthooks.ColumnJSON,
+}
+
+// fileFormat returns the format named by a flag, or else implied by the
+// file's extension, JSON lines by default.
+func fileFormat(flagValue, file string) (carthooks.Format, error) {
+	switch flagValue {
+	case "":
+		if strings.EqualFold(filepath.Ext(file), ".csv") {
+			return carthooks.FormatCSV, nil
+		}
+		return carthooks.FormatJSONLines, nil
+	case string(carthooks.FormatCSV), string(carthooks.FormatJSONLines):
+		return carthooks.Format(flagValue), nil
+	}
+	return "", usageErrorf("unknown format %q; use jsonl or csv", flagValue)
+}
+
+func (a *App) export(ctx context.Context, args []string) (err error) {
+	fs := a.flags("export")
+	var qf queryFlags
+	qf.register(fs)
+	formatFlag := fs.String("format", "", "jsonl or csv (default from the -out extension, else jsonl)")
+	columns := fs.String("columns", "", "comma-separated fields to write as CSV columns (default the fields of the first page)")
+	out := fs.String("out", "", "file to write (default stdout)")
+	args, err = parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args...)
+	if err != nil {
+		return err
+	}
+	format, err := fileFormat(*formatFlag, *out)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var queryErr error
+	opts := []carthooks.ExportOption{
+		carthooks.ExportAs(format),
+		carthooks.ExportQuery(func(q *carthooks.Query) { queryErr = qf.apply(q) }),
+	}
+	if fields := splitList(*columns); len(fields) > 0 {
+		opts = append(opts, carthooks.ExportColumns(fields...))
+	}
+	w := bufio.NewWriter(a.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = bufio.NewWriter(f)
+	}
+	err = c.Export(ctx, n[0], n[1], w, opts...)
+	if queryErr != nil {
+		return queryErr
+	}
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func (a *App) importItems(ctx context.Context, args []string) error {
+	fs := a.flags("import")
+	formatFlag := fs.String("format", "", "jsonl or csv (default from the file extension, else jsonl)")
+	columns := fs.String("columns", "", "column renames such as Title=title,Notes= (an empty name skips the column)")
+	types := fs.String("types", "", "field types such as amount=number,done=bool,tags=list; others are strings")
+	batch := fs.Int("batch", carthooks.DefaultChunkSize, "rows read and created at a time; each item is created with its own request")
+	args, err := parse(fs, args, 2, 3)
+	if err != nil {
+		return err
+	}
+	n, err := ids(args[:2]...)
+	if err != nil {
+		return err
+	}
+	var file string
+	if len(args) == 3 {
+		file = args[2]
+	}
+	format, err := fileFormat(*formatFlag, file)
+	if err != nil {
+		return err
+	}
+	opts := []carthooks.ImportOption{carthooks.ImportBatchSize(*batch)}
+	if *columns != "" {
+		m, err := parsePairs(*columns)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, carthooks.ImportColumns(m))
+	}
+	if *types != "" {
+		m, err := parsePairs(*types)
+		if err != nil {
+			return err
+		}
+		typed := make(map[string]carthooks.ColumnType, len(m))
+		for field, name := range m {
+			t, ok := columnTypes[name]
+			if !ok {
+				return usageErrorf("unknown type %q for %s; use string, number, bool, list or json", name, field)
+			}
+			typed[field] = t
+		}
+		opts = append(opts, carthooks.ImportTypes(typed))
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, err := a.openInput(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	res, err := c.Import(ctx, n[0], n[1], r, format, opts...)
+	fmt.Fprintf(a.Stderr, "%d rows read, %d items created, %d failed\n", res.Rows, len(res.Created), len(res.Failed))
+	var failed carthooks.RowErrors
+	if errors.As(err, &failed) {
+		lines := make([]int, 0, len(failed))
+		for line := range failed {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		for _, line := range lines {
+			fmt.Fprintf(a.Stderr, "line %d: %v\n", line, failed[line])
+		}
+		return fmt.Errorf("%d rows not imported", len(failed))
+	}
+	return err
+}
+
+// parsePairs parses comma-separated key=value pairs.
+func parsePairs(s string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, pair := range splitList(s) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, usageErrorf("%q is not a key=value pair", pair)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -1,43 +1,132 @@
 package carthooks
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type Client struct {
 	baseUrl     string
 	accessToken string
+	tokens      tokenSource
+	signer      Signer
 	httpClient  *http.Client
+	transport   http.RoundTripper
+	network     networkOptions
+	recorder    *recorder
+	pool        *ClientPool
+	err         error
+	timeout     time.Duration
+	clock       Clock
+	logger      Logger
+	breaker     *circuitBreaker
+	limiter     *tokenBucket
+	observers   []func(RequestInfo)
+	slots       chan struct{}
+	inFlight    atomic.Int64
+	conns       connCounters
+	rateLimit   rateLimitState
+
+	requestIDHeader string
+	userAgent       string
+	formBaseURL     string
+	requestLogger   func(method, url string, status int, dur time.Duration)
+	requestHook     func(RequestInfo)
+	tracer          Tracer
+	interceptors    []func(*http.Request) error
+	middleware      []Middleware
+	chain           RoundTripFunc
+	dataKey         string
+	naming          FieldNaming
+	codecs          map[string]fieldCodec
+	typeCodecs      map[string]fieldCodec
+	fieldTypes      map[string]string
+	hydrateCreated  bool
+	gzipThreshold   int
+	paginationFn    PaginationExtractor
+	maxAttempts     int
+	retryBase       time.Duration
+	backoff         Backoff
+	retryJitter     float64
+
+	autoIdempotencyKeys bool
+
+	aggregates   *ttlCache
+	aggregateTTL time.Duration
+	schema       *ttlCache
+	schemaTTL    time.Duration
+	slugs        slugCache
+	cache        CacheStore
+	cacheTTL     time.Duration
+	// cacheNamespace prefixes the keys of cache, so the clients of a
+	// ClientPool do not see each other's responses.
+	cacheNamespace string
 }
 
-func NewClient(accessToken string) *Client {
+func NewClient(accessToken string, opts ...Option) *Client {
 	c := &Client{
-		accessToken: accessToken,
+		accessToken:     accessToken,
+		clock:           realClock{},
+		logger:          NopLogger{},
+		aggregates:      newTTLCache(),
+		schema:          newTTLCache(),
+		requestIDHeader: defaultRequestIDHeader,
+		dataKey:         defaultDataKey,
 	}
 	if os.Getenv("CARTHOOKS_API_URL") != "" {
-		c.baseUrl = os.Getenv("CARTHOOKS_API_URL")
+		c.baseUrl = strings.TrimRight(os.Getenv("CARTHOOKS_API_URL"), "/")
 	} else {
 		c.baseUrl = "https://api.carthooks.com"
 	}
-	c.httpClient = &http.Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.applyTypeCodecs()
+	if c.pool != nil {
+		// Clients of a pool share its HTTP client, built from the same
+		// options.
+		c.httpClient, c.err = c.pool.httpClient, c.pool.err
+	} else {
+		c.setupHTTPClient()
+	}
+	c.buildChain()
 	return c
 }
 
+// NewClientWithOptions returns a client authenticated with accessToken and
+// configured by opts. It is the same as NewClient.
+func NewClientWithOptions(accessToken string, opts ...Option) *Client {
+	return NewClient(accessToken, opts...)
+}
+
 type Query struct {
 	client       *Client
 	appID        int
 	collectionID int
 	limit        int
-	filters      map[string]map[string]string
+	filters      []filter
 	page         int
-	sort         string
+	sort         []string
+	withoutCount bool
+	maxPages     int
+	maxPagesSet  bool
+	fields       []string
+	populate     []string
+	search       string
+	or           [][]conditions
+	cursor       string
+	cursorMode   bool
+	// err is the first invalid condition added with Where; requests fail
+	// with it instead of being sent.
+	err error
 }
 
 func (q *Query) Limit(limit int) *Query {
@@ -45,12 +134,124 @@ func (q *Query) Limit(limit int) *Query {
 	return q
 }
 
+// PageSize is Limit.
+func (q *Query) PageSize(n int) *Query {
+	return q.Limit(n)
+}
+
+// Select asks the server to return only the named fields of each item,
+// sent as fields[0]=title&fields[1]=status in the order given. Repeated
+// calls add to the selection.
+func (q *Query) Select(fields ...string) *Query {
+	q.fields = append(q.fields, fields...)
+	return q
+}
+
+// Populate asks the server to expand the named relation fields into the
+// related items instead of returning only their IDs, sent as
+// populate[0]=owner&populate[1]=tags. "*" expands all relations one level
+// deep. Repeated calls add to the list.
+func (q *Query) Populate(fields ...string) *Query {
+	q.populate = append(q.populate, fields...)
+	return q
+}
+
+// Page selects the 1-based page to fetch.
+func (q *Query) Page(page int) *Query {
+	q.page = page
+	return q
+}
+
+// WithoutCount asks the server to skip computing the total and page count,
+// which makes listing cheaper when totals are not needed. The pagination
+// meta of such responses carries no total or pageCount.
+func (q *Query) WithoutCount() *Query {
+	q.withoutCount = true
+	return q
+}
+
 type Item struct {
 	ID     int
 	Fields map[string]interface{}
+
+	// CreatedAt and UpdatedAt are parsed from the item's system fields. They
+	// are zero when the server did not send them. The raw values stay in
+	// Fields.
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	// ETag is the item's version as reported by the server on reads, for
+	// use with IfNoneMatch and IfMatch.
+	ETag string `json:"-"`
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type item Item
+	if err := json.Unmarshal(data, (*item)(i)); err != nil {
+		return err
+	}
+	i.CreatedAt = fieldTime(i.Fields, "createdAt", "created_at")
+	i.UpdatedAt = fieldTime(i.Fields, "updatedAt", "updated_at")
+	return nil
+}
+
+// IsSystemField reports whether a field of an item is maintained by the
+// server, like its ID and timestamps, and so is not written back.
+func IsSystemField(field string) bool {
+	switch field {
+	case "id", "createdAt", "updatedAt", "created_at", "updated_at":
+		return true
+	}
+	return false
+}
+
+// fieldTime parses the first of keys present in fields as an RFC 3339 time.
+func fieldTime(fields map[string]interface{}, keys ...string) time.Time {
+	for _, key := range keys {
+		s, ok := fields[key].(string)
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
 }
 
 func (q *Query) Get() ([]Item, error) {
+	return q.GetContext(context.Background())
+}
+
+// GetContext is like Get but aborts the request when ctx is done.
+func (q *Query) GetContext(ctx context.Context) ([]Item, error) {
+	_, items, err := q.fetch(ctx, q.params())
+	return items, err
+}
+
+// GetWithResponse is like GetContext but also returns the response, e.g. for
+// its TraceId or Meta.
+func (q *Query) GetWithResponse(ctx context.Context) ([]Item, *Response, error) {
+	rsp, items, err := q.fetch(ctx, q.params())
+	return items, rsp, err
+}
+
+// GetWithMeta is like GetContext but also returns the pagination of the
+// page, for paginated listings. The pagination is nil if the response
+// carries none.
+func (q *Query) GetWithMeta(ctx context.Context) ([]Item, *Pagination, error) {
+	rsp, items, err := q.fetch(ctx, q.params())
+	if err != nil {
+		return nil, nil, err
+	}
+	if p, ok := q.client.pagination(rsp.Meta); ok {
+		return items, &p, nil
+	}
+	return items, nil, nil
+}
+
+// params serializes the query into the API's list parameters.
+func (q *Query) params() url.Values {
 	params := url.Values{}
 	if q.limit > 0 {
 		params.Add("pagination[pageSize]", strconv.Itoa(int(q.limit)))
@@ -58,25 +259,44 @@ func (q *Query) Get() ([]Item, error) {
 	if q.page > 0 {
 		params.Add("pagination[page]", strconv.Itoa(int(q.page)))
 	}
-	if q.sort != "" {
-		params.Add("sort", q.sort)
+	if len(q.sort) > 0 {
+		params.Add("sort", strings.Join(q.sort, ","))
+	}
+	if q.withoutCount {
+		params.Add("pagination[withCount]", "false")
 	}
-	for field, operators := range q.filters {
-		for operator, value := range operators {
-			params.Add("filters["+field+"]["+operator+"]", value)
+	addFilterParams(params, "filters", q.filters, q.or)
+	if q.search != "" {
+		params.Add("_q", q.search)
+	}
+	addSelectParams(params, q.fields)
+	if len(q.populate) == 1 && q.populate[0] == "*" {
+		params.Add("populate", "*")
+	} else {
+		for i, field := range q.populate {
+			params.Add("populate["+strconv.Itoa(i)+"]", field)
 		}
 	}
-	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items?%s",
+	return params
+}
+
+func (q *Query) itemsURL(params url.Values) string {
+	return fmt.Sprintf("%s/v1/apps/%d/collections/%d/items?%s",
 		q.client.baseUrl, q.appID, q.collectionID, params.Encode())
-	fmt.Println(urladdr)
-	rst, err := q.client.Get(urladdr)
+}
+
+func (q *Query) fetch(ctx context.Context, params url.Values) (*Response, []Item, error) {
+	if q.err != nil {
+		return nil, nil, q.err
+	}
+	rst, err := q.client.GetContext(ctx, q.itemsURL(params))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	items := []Item{}
 	err = rst.Bind(&items)
-	return items, err
+	return rst, items, err
 }
 
 type Response struct {
@@ -84,10 +304,27 @@ type Response struct {
 	Meta    map[string]interface{} `json:"meta"`
 	TraceId string                 `json:"trace_id"`
 	Error   *ResponseError         `json:"error"`
+
+	// RequestID is the request ID echoed by the server, if any.
+	RequestID string `json:"-"`
+
+	// ETag is the entity tag of the returned resource, if any.
+	ETag string `json:"-"`
+
+	// Header holds the HTTP response headers.
+	Header http.Header `json:"-"`
+
+	client *Client
 }
 
 func (r *Response) Bind(v interface{}) error {
-	return json.Unmarshal(r.Data, v)
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return err
+	}
+	if r.client == nil {
+		return nil
+	}
+	return r.client.decodeItems(v)
 }
 
 type ResponseError struct {
@@ -96,128 +333,233 @@ type ResponseError struct {
 	Key     string `json:"key"`
 }
 
+func (e *ResponseError) Error() string {
+	return "error: " + e.Key
+}
+
 func (c *Client) Get(url string) (*Response, error) {
 	return c.Request(http.MethodGet, url, nil)
 }
 
+func (c *Client) GetContext(ctx context.Context, url string) (*Response, error) {
+	return c.RequestContext(ctx, http.MethodGet, url, nil)
+}
+
 func (c *Client) Post(url string, body map[string]any) (*Response, error) {
 	return c.Request(http.MethodPost, url, body)
 }
 
+func (c *Client) PostContext(ctx context.Context, url string, body map[string]any) (*Response, error) {
+	return c.RequestContext(ctx, http.MethodPost, url, body)
+}
+
 func (c *Client) Request(method, url string, body map[string]any) (*Response, error) {
+	return c.RequestContext(context.Background(), method, url, body)
+}
 
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) RequestContext(ctx context.Context, method, url string, body map[string]any) (*Response, error) {
+	return c.do(ctx, method, url, jsonBody(body), nil)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	if c.accessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	}
+// PostForm sends form as an application/x-www-form-urlencoded body instead
+// of JSON.
+func (c *Client) PostForm(ctx context.Context, url string, form url.Values) (*Response, error) {
+	return c.do(ctx, http.MethodPost, url, formBody(form), nil)
+}
+
+// filter is a single field condition. Conditions are ANDed together, so one
+// field may carry several operators, e.g. $gte and $lte for a range.
+type filter struct {
+	field    string
+	operator string
+	value    string
+	// values holds the operands of a multi-value operator such as $in; it
+	// is nil for single-value filters.
+	values []string
+}
 
-	if body != nil {
-		jsondata, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
+// Filter adds the condition field <operator> value. Calling it again with the
+// same field and operator replaces the earlier value; other operators on the
+// same field are kept.
+func (q *Query) Filter(field, operator, value string) *Query {
+	for i, f := range q.filters {
+		if f.field == field && f.operator == operator {
+			q.filters[i].value, q.filters[i].values = value, nil
+			return q
 		}
-		req.Body = ioutil.NopCloser(bytes.NewReader(jsondata))
 	}
+	q.filters = append(q.filters, filter{field: field, operator: operator, value: value})
+	return q
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+func (c *Client) Query(appID, collectionID int) *Query {
+	return &Query{
+		client:       c,
+		appID:        appID,
+		collectionID: collectionID,
 	}
+}
 
-	defer resp.Body.Close()
+// ItemOption customizes a single-item read such as GetItemByID.
+type ItemOption func(*itemOptions)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+type itemOptions struct {
+	params url.Values
+	header http.Header
+	fields []string
+}
+
+// WithFields asks the server to return only the named fields of the item.
+func WithFields(fields ...string) ItemOption {
+	return func(o *itemOptions) {
+		o.fields = append(o.fields, fields...)
 	}
+}
 
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// IfNoneMatch makes the read conditional: if the item still has the given
+// ETag the call fails with ErrNotModified and the cached copy can be used.
+func IfNoneMatch(etag string) ItemOption {
+	return func(o *itemOptions) {
+		o.header.Set("If-None-Match", etag)
 	}
+}
 
-	result := Response{}
-	err = json.Unmarshal(data, &result)
-	if err != nil {
-		return nil, err
+// WithIncludeTrashed lets the read return an item that is in the trash.
+// Without it such an item is reported as not found.
+func WithIncludeTrashed() ItemOption {
+	return func(o *itemOptions) {
+		o.params.Set("includeTrashed", "true")
 	}
+}
 
-	if result.Error != nil {
-		return nil, fmt.Errorf("error: %s", result.Error.Key)
+// addSelectParams serializes a field projection as fields[0]=a&fields[1]=b.
+func addSelectParams(params url.Values, fields []string) {
+	for i, field := range fields {
+		params.Add("fields["+strconv.Itoa(i)+"]", field)
 	}
+}
 
-	return &result, nil
+func (c *Client) GetItemByID(appID, collectionID, itemID int, opts ...ItemOption) (*Item, error) {
+	return c.GetItemByIDContext(context.Background(), appID, collectionID, itemID, opts...)
 }
 
-func (q *Query) Filter(field, operator, value string) *Query {
-	if q.filters == nil {
-		q.filters = make(map[string]map[string]string)
-	}
-	if q.filters[field] == nil {
-		q.filters[field] = make(map[string]string)
-	}
-	q.filters[field][operator] = value
-	return q
+func (c *Client) GetItemByIDContext(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Item, error) {
+	item, _, err := c.GetItemByIDWithResponse(ctx, appID, collectionID, itemID, opts...)
+	return item, err
 }
 
-func (c *Client) Query(appID, collectionID int) *Query {
-	return &Query{
-		client:       c,
-		appID:        appID,
-		collectionID: collectionID,
+// GetItemByIDWithResponse is like GetItemByIDContext but also returns the
+// response, e.g. for its TraceId.
+func (c *Client) GetItemByIDWithResponse(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Item, *Response, error) {
+	rsp, err := c.getItem(ctx, appID, collectionID, itemID, opts...)
+	if err != nil {
+		return nil, nil, err
 	}
+	item := Item{}
+	err = rsp.Bind(&item)
+	item.ETag = rsp.ETag
+	return &item, rsp, err
 }
 
-func (c *Client) GetItemByID(appID, collectionID, itemID int) (*Item, error) {
+// getItem performs a single-item read and returns the raw response.
+func (c *Client) getItem(ctx context.Context, appID, collectionID, itemID int, opts ...ItemOption) (*Response, error) {
+	o := itemOptions{params: url.Values{}, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	addSelectParams(o.params, o.fields)
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d",
 		c.baseUrl, appID, collectionID, itemID)
-	rsp, err := c.Get(urladdr)
-	if err != nil {
-		return nil, err
+	if len(o.params) > 0 {
+		urladdr += "?" + o.params.Encode()
 	}
-	item := Item{}
-	err = rsp.Bind(&item)
-	return &item, err
+	return c.do(ctx, http.MethodGet, urladdr, nil, o.header)
 }
 
 func (c *Client) GetSubmissionToken(appID, collectionID int, options map[string]interface{}) (*Response, error) {
+	return c.GetSubmissionTokenContext(context.Background(), appID, collectionID, options)
+}
+
+func (c *Client) GetSubmissionTokenContext(ctx context.Context, appID, collectionID int, options map[string]interface{}) (*Response, error) {
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/submission-token",
 		c.baseUrl, appID, collectionID)
-	return c.Post(urladdr, options)
+	return c.PostContext(ctx, urladdr, options)
 }
 
 func (c *Client) UpdateSubmissionToken(appID, collectionID, itemID int, options map[string]interface{}) (*Response, error) {
+	return c.UpdateSubmissionTokenContext(context.Background(), appID, collectionID, itemID, options)
+}
+
+func (c *Client) UpdateSubmissionTokenContext(ctx context.Context, appID, collectionID, itemID int, options map[string]interface{}) (*Response, error) {
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/update-token",
 		c.baseUrl, appID, collectionID, itemID)
-	return c.Post(urladdr, options)
+	return c.PostContext(ctx, urladdr, options)
 }
 
 func (c *Client) CreateItem(appID, collectionID int, data map[string]interface{}) (item *Item, err error) {
+	return c.CreateItemContext(context.Background(), appID, collectionID, data)
+}
+
+// CreateItemWithKey creates an item sending key as its Idempotency-Key, so
+// that retrying the call with the same key does not create a duplicate
+// where the server honors the header.
+func (c *Client) CreateItemWithKey(ctx context.Context, appID, collectionID int, data map[string]interface{}, key string) (*Item, error) {
+	return c.CreateItemContext(ContextWithIdempotencyKey(ctx, key), appID, collectionID, data)
+}
+
+func (c *Client) CreateItemContext(ctx context.Context, appID, collectionID int, data map[string]interface{}) (item *Item, err error) {
+	item, _, err = c.CreateItemWithResponse(ctx, appID, collectionID, data)
+	return item, err
+}
+
+// CreateItemWithResponse is like CreateItemContext but also returns the
+// response, e.g. for its TraceId. With WithHydrateCreated, it is the
+// response of the follow-up read.
+func (c *Client) CreateItemWithResponse(ctx context.Context, appID, collectionID int, data map[string]interface{}, opts ...WriteOption) (*Item, *Response, error) {
+	o := newWriteOptions(opts)
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items",
 		c.baseUrl, appID, collectionID)
-	rsp, err := c.Post(urladdr, map[string]any{"data": data})
+	data, err := c.encodeFields(data)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	item = &Item{}
-	err = rsp.Bind(item)
-	return item, err
+	rsp, err := c.do(ctx, http.MethodPost, urladdr, jsonBody(c.envelope(data)), o.header)
+	if err != nil {
+		return nil, nil, err
+	}
+	item := &Item{}
+	if err := rsp.Bind(item); err != nil {
+		return item, rsp, err
+	}
+	if c.hydrateCreated && item.ID != 0 && len(item.Fields) == 0 {
+		return c.GetItemByIDWithResponse(ctx, appID, collectionID, item.ID)
+	}
+	return item, rsp, nil
 }
 
 func (c *Client) UpdateItem(appID, collectionID, itemID int, data map[string]interface{}) (*Response, error) {
+	return c.UpdateItemContext(context.Background(), appID, collectionID, itemID, data)
+}
+
+func (c *Client) UpdateItemContext(ctx context.Context, appID, collectionID, itemID int, data map[string]interface{}, opts ...WriteOption) (*Response, error) {
+	o := newWriteOptions(opts)
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d",
 		c.baseUrl, appID, collectionID, itemID)
-	return c.Request(http.MethodPut, urladdr, map[string]any{"data": data})
+	data, err := c.encodeFields(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, http.MethodPut, urladdr, jsonBody(c.envelope(data)), o.header)
 }
 
 func (c *Client) LockItem(appID, collectionID, itemID, lockTimeout int, lockID, subject string) (*Response, error) {
+	return c.LockItemContext(context.Background(), appID, collectionID, itemID, lockTimeout, lockID, subject)
+}
+
+func (c *Client) LockItemContext(ctx context.Context, appID, collectionID, itemID, lockTimeout int, lockID, subject string) (*Response, error) {
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/lock",
 		c.baseUrl, appID, collectionID, itemID)
-	return c.Post(urladdr, map[string]any{
+	return c.PostContext(ctx, urladdr, map[string]any{
 		"lockTimeout": lockTimeout,
 		"lockId":      lockID,
 		"lockSubject": subject,
@@ -225,18 +567,34 @@ func (c *Client) LockItem(appID, collectionID, itemID, lockTimeout int, lockID,
 }
 
 func (c *Client) UnlockItem(appID, collectionID, itemID int, lockID string) (*Response, error) {
+	return c.UnlockItemContext(context.Background(), appID, collectionID, itemID, lockID)
+}
+
+func (c *Client) UnlockItemContext(ctx context.Context, appID, collectionID, itemID int, lockID string) (*Response, error) {
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/unlock",
 		c.baseUrl, appID, collectionID, itemID)
-	return c.Post(urladdr, map[string]any{"lockId": lockID})
+	return c.PostContext(ctx, urladdr, map[string]any{"lockId": lockID})
 }
 
-func (c *Client) DeleteItem(appID, collectionID, itemID int) (*Response, error) {
+// DeleteItem deletes an item. A 200 or 204 response means it was deleted; a
+// missing item yields an error matching ErrNotFound. See DeleteItemIfExists
+// for idempotent cleanup.
+func (c *Client) DeleteItem(appID, collectionID, itemID int, opts ...WriteOption) (*Response, error) {
+	return c.DeleteItemContext(context.Background(), appID, collectionID, itemID, opts...)
+}
+
+func (c *Client) DeleteItemContext(ctx context.Context, appID, collectionID, itemID int, opts ...WriteOption) (*Response, error) {
+	o := newWriteOptions(opts)
 	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d",
 		c.baseUrl, appID, collectionID, itemID)
-	return c.Request(http.MethodDelete, urladdr, nil)
+	return c.do(ctx, http.MethodDelete, urladdr, nil, o.header)
 }
 
 func (c *Client) GetUploadToken() (*Response, error) {
+	return c.GetUploadTokenContext(context.Background())
+}
+
+func (c *Client) GetUploadTokenContext(ctx context.Context) (*Response, error) {
 	urladdr := fmt.Sprintf("%s/v1/uploads/token", c.baseUrl)
-	return c.Post(urladdr, nil)
+	return c.PostContext(ctx, urladdr, nil)
 }
@@ -0,0 +1,105 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestQuerySelect(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	s.AddItem(1, 2, map[string]interface{}{"title": "a", "status": "open", "body": "long text"})
+	c := s.Client()
+
+	query := func() *carthooks.Query {
+		return c.Query(1, 2).
+			Where("status", carthooks.OpEq, "open").
+			OrderBy("title", carthooks.Asc).
+			Select("title", "status").
+			Page(1).
+			Limit(10)
+	}
+	var rawQueries []string
+	for i := 0; i < 3; i++ {
+		items, err := query().GetContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 {
+			t.Fatalf("got %d items, want 1", len(items))
+		}
+		if want := map[string]interface{}{"title": "a", "status": "open"}; !reflect.DeepEqual(items[0].Fields, want) {
+			t.Errorf("got fields %v, want %v", items[0].Fields, want)
+		}
+		reqs := s.Requests()
+		rawQueries = append(rawQueries, reqs[len(reqs)-1].RawQuery)
+	}
+	for _, raw := range rawQueries[1:] {
+		if raw != rawQueries[0] {
+			t.Errorf("query string changed between runs: %q and %q", rawQueries[0], raw)
+		}
+	}
+	if !strings.Contains(rawQueries[0], "fields%5B0%5D=title&fields%5B1%5D=status") {
+		t.Errorf("query string %q lacks fields[0]=title&fields[1]=status", rawQueries[0])
+	}
+}
+
+func TestCreateItemSendsContentLength(t *testing.T) {
+	var (
+		body   []byte
+		length int64
+		chunks []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		length, chunks = r.ContentLength, r.TransferEncoding
+		io.WriteString(w, `{"data":{"id":1,"fields":{"title":"a"}}}`)
+	}))
+	defer s.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+
+	if _, err := c.CreateItem(1, 2, map[string]interface{}{"title": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"data":{"title":"a"}}`; string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+	if length != int64(len(body)) || length == 0 {
+		t.Errorf("Content-Length = %d, want %d", length, len(body))
+	}
+	if len(chunks) > 0 {
+		t.Errorf("Transfer-Encoding = %v, want none", chunks)
+	}
+}
+
+func TestQueryFilterRange(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	for _, amount := range []int{5, 50, 500} {
+		s.AddItem(1, 2, map[string]interface{}{"amount": amount})
+	}
+	c := s.Client()
+
+	items, err := c.Query(1, 2).Filter("amount", "$gte", "10").Filter("amount", "$lte", "100").GetContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Fields["amount"] != float64(50) {
+		t.Errorf("got %v, want the item with amount 50", items)
+	}
+	query := s.Requests()[0].Query
+	if got := query.Get("filters[amount][$gte]"); got != "10" {
+		t.Errorf("filters[amount][$gte] = %q, want 10", got)
+	}
+	if got := query.Get("filters[amount][$lte]"); got != "100" {
+		t.Errorf("filters[amount][$lte] = %q, want 100", got)
+	}
+}
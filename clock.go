@@ -0,0 +1,21 @@
+package carthooks
+
+import "time"
+
+// Clock is the source of time for expiry, TTL and backoff logic. The default
+// uses the system clock; tests can supply their own via WithClock to advance
+// time deterministically.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
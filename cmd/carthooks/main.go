@@ -0,0 +1,30 @@
+// Command carthooks is a command-line client for the Carthooks API. Run
+// carthooks -h for its commands; see package cli for details.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/carthooks/carthooks-sdk-golang/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := (&cli.App{}).Run(ctx, os.Args[1:])
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, cli.ErrUsage):
+		fmt.Fprintln(os.Stderr, "carthooks:", err)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "carthooks:", err)
+		os.Exit(1)
+	}
+}
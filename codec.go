@@ -0,0 +1,160 @@
+package carthooks
+
+import (
+	"fmt"
+	"time"
+)
+
+// fieldCodec transforms a field value on its way to and from the API.
+type fieldCodec struct {
+	encode func(interface{}) (interface{}, error)
+	decode func(interface{}) (interface{}, error)
+}
+
+// WithFieldCodec registers transforms for one item field, e.g. to encrypt a
+// sensitive value before it is sent. encode is applied to the field in create
+// and update payloads, decode to the field of items read back. Either may be
+// nil. Fields without a codec pass through untouched.
+func WithFieldCodec(field string, encode, decode func(interface{}) (interface{}, error)) Option {
+	return func(c *Client) {
+		if c.codecs == nil {
+			c.codecs = map[string]fieldCodec{}
+		}
+		c.codecs[field] = fieldCodec{encode: encode, decode: decode}
+	}
+}
+
+// Codec converts the values of a field type between their API form and the
+// Go values callers work with. Encode is applied to write payloads, Decode to
+// items read back; either may be nil.
+type Codec struct {
+	Encode func(interface{}) (interface{}, error)
+	Decode func(interface{}) (interface{}, error)
+}
+
+// WithTypeCodec registers codec for every field of the given type, such as
+// "date" or "money", as declared with WithFieldTypes. A codec registered for
+// the field itself with WithFieldCodec takes precedence.
+func WithTypeCodec(fieldType string, codec Codec) Option {
+	return func(c *Client) {
+		if c.typeCodecs == nil {
+			c.typeCodecs = map[string]fieldCodec{}
+		}
+		c.typeCodecs[fieldType] = fieldCodec{encode: codec.Encode, decode: codec.Decode}
+	}
+}
+
+// WithFieldTypes declares the types of item fields by name, for the codecs
+// registered with WithTypeCodec. Collection.FieldTypes lists them from a
+// collection's schema:
+//
+//	col, err := c.GetCollection(ctx, appID, collectionID)
+//	...
+//	c = carthooks.NewClient(token,
+//		carthooks.WithFieldTypes(col.FieldTypes()),
+//		carthooks.WithTypeCodec("date", carthooks.TimeCodec))
+func WithFieldTypes(types map[string]string) Option {
+	return func(c *Client) {
+		if c.fieldTypes == nil {
+			c.fieldTypes = map[string]string{}
+		}
+		for field, fieldType := range types {
+			c.fieldTypes[field] = fieldType
+		}
+	}
+}
+
+// applyTypeCodecs gives each field of a declared type the codec of its type,
+// unless the field has a codec of its own.
+func (c *Client) applyTypeCodecs() {
+	for field, fieldType := range c.fieldTypes {
+		codec, ok := c.typeCodecs[fieldType]
+		if !ok {
+			continue
+		}
+		if _, ok := c.codecs[field]; ok {
+			continue
+		}
+		if c.codecs == nil {
+			c.codecs = map[string]fieldCodec{}
+		}
+		c.codecs[field] = codec
+	}
+}
+
+// TimeCodec maps date and date-time fields to time.Time. It decodes RFC 3339
+// timestamps and plain dates (2006-01-02) and encodes a time.Time as an RFC
+// 3339 timestamp in UTC. Other values, such as nil, pass through.
+var TimeCodec = Codec{
+	Encode: func(v interface{}) (interface{}, error) {
+		if t, ok := v.(time.Time); ok {
+			return t.UTC().Format(time.RFC3339Nano), nil
+		}
+		return v, nil
+	},
+	Decode: func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok || s == "" {
+			return v, nil
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", s)
+	},
+}
+
+// encodeFields applies the registered encoders to a write payload. The
+// caller's map is left unmodified.
+func (c *Client) encodeFields(data map[string]interface{}) (map[string]interface{}, error) {
+	if len(c.codecs) == 0 {
+		return data, nil
+	}
+	out := make(map[string]interface{}, len(data))
+	for field, value := range data {
+		if codec, ok := c.codecs[field]; ok && codec.encode != nil {
+			v, err := codec.encode(value)
+			if err != nil {
+				return nil, fmt.Errorf("carthooks: encode field %q: %w", field, err)
+			}
+			value = v
+		}
+		out[field] = value
+	}
+	return out, nil
+}
+
+// decodeFields applies the registered decoders to the fields of an item read
+// from the API.
+func (c *Client) decodeFields(item *Item) error {
+	for field, codec := range c.codecs {
+		value, ok := item.Fields[field]
+		if !ok || codec.decode == nil {
+			continue
+		}
+		v, err := codec.decode(value)
+		if err != nil {
+			return fmt.Errorf("carthooks: decode field %q of item %d: %w", field, item.ID, err)
+		}
+		item.Fields[field] = v
+	}
+	return nil
+}
+
+// decodeItems applies the field decoders to v if it holds items.
+func (c *Client) decodeItems(v interface{}) error {
+	if len(c.codecs) == 0 {
+		return nil
+	}
+	switch v := v.(type) {
+	case *Item:
+		return c.decodeFields(v)
+	case *[]Item:
+		for i := range *v {
+			if err := c.decodeFields(&(*v)[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
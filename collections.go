@@ -0,0 +1,136 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Collection describes a collection and, when read with GetCollection, the
+// definitions of its fields.
+type Collection struct {
+	ID          int               `json:"id,omitempty"`
+	AppID       int               `json:"appId,omitempty"`
+	Name        string            `json:"name"`
+	Slug        string            `json:"slug,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Fields      []FieldDefinition `json:"fields,omitempty"`
+}
+
+// FieldDefinition is the schema of one field of a collection.
+type FieldDefinition struct {
+	// Name is the key of the field in Item.Fields.
+	Name     string        `json:"name"`
+	Label    string        `json:"label,omitempty"`
+	Type     string        `json:"type"`
+	Required bool          `json:"required,omitempty"`
+	Unique   bool          `json:"unique,omitempty"`
+	Options  []FieldOption `json:"options,omitempty"`
+	// Settings holds type-specific settings, such as the target collection
+	// of a relation field.
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// FieldTypes maps the names of the collection's fields to their types, for
+// WithFieldTypes.
+func (col *Collection) FieldTypes() map[string]string {
+	types := make(map[string]string, len(col.Fields))
+	for _, f := range col.Fields {
+		types[f.Name] = f.Type
+	}
+	return types
+}
+
+// ListCollections returns the collections of an app. Their Fields are not
+// necessarily filled in; use GetCollection for the full schema.
+func (c *Client) ListCollections(ctx context.Context, appID int) ([]Collection, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections", c.baseUrl, appID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	collections := []Collection{}
+	if err := rsp.Bind(&collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// GetCollection returns a collection with its field definitions.
+func (c *Client) GetCollection(ctx context.Context, appID, collectionID int) (*Collection, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d", c.baseUrl, appID, collectionID)
+	return c.collectionRequest(ctx, http.MethodGet, urladdr, nil)
+}
+
+// CreateCollection creates a collection in an app, including any fields
+// listed in collection, and returns it as stored.
+func (c *Client) CreateCollection(ctx context.Context, appID int, collection Collection) (*Collection, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections", c.baseUrl, appID)
+	return c.collectionRequest(ctx, http.MethodPost, urladdr, jsonBody(c.envelope(collection)))
+}
+
+// UpdateCollection changes the name, slug and description of a collection.
+// Fields are managed with AddField, UpdateField and DeleteField.
+func (c *Client) UpdateCollection(ctx context.Context, appID, collectionID int, collection Collection) (*Collection, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d", c.baseUrl, appID, collectionID)
+	collection.Fields = nil
+	return c.collectionRequest(ctx, http.MethodPut, urladdr, jsonBody(c.envelope(collection)))
+}
+
+// DeleteCollection deletes a collection together with all its items.
+func (c *Client) DeleteCollection(ctx context.Context, appID, collectionID int) error {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d", c.baseUrl, appID, collectionID)
+	_, err := c.do(ctx, http.MethodDelete, urladdr, nil, nil)
+	return err
+}
+
+func (c *Client) collectionRequest(ctx context.Context, method, urladdr string, body requestBody) (*Collection, error) {
+	rsp, err := c.do(ctx, method, urladdr, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	collection := &Collection{}
+	if err := rsp.Bind(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// AddField adds a field to a collection and returns its definition as
+// stored.
+func (c *Client) AddField(ctx context.Context, appID, collectionID int, field FieldDefinition) (*FieldDefinition, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/fields", c.baseUrl, appID, collectionID)
+	defer c.forgetSchema(appID, collectionID)
+	return c.fieldRequest(ctx, http.MethodPost, urladdr, jsonBody(c.envelope(field)))
+}
+
+// UpdateField changes the definition of the named field. Changing its type
+// may be refused by the server if existing values cannot be converted.
+func (c *Client) UpdateField(ctx context.Context, appID, collectionID int, name string, field FieldDefinition) (*FieldDefinition, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/fields/%s",
+		c.baseUrl, appID, collectionID, url.PathEscape(name))
+	defer c.forgetSchema(appID, collectionID)
+	return c.fieldRequest(ctx, http.MethodPut, urladdr, jsonBody(c.envelope(field)))
+}
+
+// DeleteField removes the named field and its values from a collection.
+func (c *Client) DeleteField(ctx context.Context, appID, collectionID int, name string) error {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/fields/%s",
+		c.baseUrl, appID, collectionID, url.PathEscape(name))
+	defer c.forgetSchema(appID, collectionID)
+	_, err := c.do(ctx, http.MethodDelete, urladdr, nil, nil)
+	return err
+}
+
+func (c *Client) fieldRequest(ctx context.Context, method, urladdr string, body requestBody) (*FieldDefinition, error) {
+	rsp, err := c.do(ctx, method, urladdr, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldDefinition{}
+	if err := rsp.Bind(field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
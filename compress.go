@@ -0,0 +1,33 @@
+package carthooks
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// WithRequestCompression gzips request bodies of at least threshold bytes
+// and sends them with Content-Encoding: gzip. Only enable it for servers
+// that accept compressed requests. It is off by default; a threshold of
+// zero or less turns it off.
+func WithRequestCompression(threshold int) Option {
+	return func(c *Client) {
+		c.gzipThreshold = threshold
+	}
+}
+
+// shouldCompress reports whether a payload of n bytes is to be gzipped.
+func (c *Client) shouldCompress(n int) bool {
+	return c.gzipThreshold > 0 && n >= c.gzipThreshold
+}
+
+func gzipBytes(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
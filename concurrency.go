@@ -0,0 +1,59 @@
+package carthooks
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// WithMaxConcurrentRequests limits the number of requests the client has in
+// flight at once, across all goroutines. Further requests wait for a free
+// slot or for their context to be done. A request holds its slot until its
+// response body has been read and closed.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.slots = make(chan struct{}, n)
+		} else {
+			c.slots = nil
+		}
+	}
+}
+
+// InFlight returns the number of requests currently in flight.
+func (c *Client) InFlight() int {
+	return int(c.inFlight.Load())
+}
+
+// acquire waits for a request slot and returns the function releasing it.
+func (c *Client) acquire(ctx context.Context) (release func(), err error) {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.inFlight.Add(-1)
+			if c.slots != nil {
+				<-c.slots
+			}
+		})
+	}, nil
+}
+
+// releasingBody releases a request slot when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := drainAndClose(b.ReadCloser)
+	b.release()
+	return err
+}
@@ -0,0 +1,42 @@
+package carthooks
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// ConnStats counts the connections the client's requests went out on.
+type ConnStats struct {
+	// Reused counts requests sent on a pooled, previously used connection.
+	Reused int64
+	// New counts requests that had to open a new connection.
+	New int64
+}
+
+type connCounters struct {
+	reused atomic.Int64
+	new    atomic.Int64
+}
+
+// ConnStats reports how often requests reused a pooled connection, which
+// shows whether the transport's pooling is effective.
+func (c *Client) ConnStats() ConnStats {
+	return ConnStats{Reused: c.conns.reused.Load(), New: c.conns.new.Load()}
+}
+
+// traceConn attaches an httptrace hook to req that counts connection reuse
+// and notes it in info.
+func (c *Client) traceConn(req *http.Request, info *RequestInfo) *http.Request {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(conn httptrace.GotConnInfo) {
+			info.ConnReused = conn.Reused
+			if conn.Reused {
+				c.conns.reused.Add(1)
+			} else {
+				c.conns.new.Add(1)
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
@@ -0,0 +1,59 @@
+package carthooks
+
+import "reflect"
+
+// Into fills the struct pointed to by v from the item. Struct fields are
+// matched to item fields by their json tag, or by their Go name if untagged;
+// an "id" field receives the item ID. Values are converted through JSON, so
+// file fields can be decoded into []Attachment, relation fields into []int
+// and select fields into []string.
+//
+// Item fields with no matching struct field are kept in the struct's
+// map[string]interface{} field tagged `carthooks:"extra"`, if there is one.
+// Use Client.DecodeItem to apply the client's field naming instead.
+func (item *Item) Into(v interface{}) error {
+	return decodeStruct(item.fieldsWithID(), v, nil)
+}
+
+// fieldsWithID returns the item's fields including its ID.
+func (item *Item) fieldsWithID() map[string]interface{} {
+	if _, ok := item.Fields["id"]; ok || item.ID == 0 {
+		return item.Fields
+	}
+	fields := make(map[string]interface{}, len(item.Fields)+1)
+	for name, value := range item.Fields {
+		fields[name] = value
+	}
+	fields["id"] = item.ID
+	return fields
+}
+
+// ItemFromStruct builds an item from the exported fields of the struct v,
+// named as in Item.Into. An "id" field sets the item ID instead of a field.
+// The field tagged `carthooks:"extra"` is ignored, so fields preserved on
+// read are not written back.
+func ItemFromStruct(v interface{}) (*Item, error) {
+	fields, err := encodeStruct(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	item := &Item{Fields: fields}
+	if id, ok := fields["id"]; ok {
+		if n, ok := intValue(id); ok {
+			item.ID = n
+			delete(fields, "id")
+		}
+	}
+	return item, nil
+}
+
+func intValue(v interface{}) (int, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), true
+	}
+	return 0, false
+}
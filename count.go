@@ -0,0 +1,126 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNoTotal is returned when a list response carries no pagination total.
+var ErrNoTotal = errors.New("carthooks: response has no pagination total")
+
+// WithAggregateCacheTTL caches aggregate reads such as CollectionCount,
+// GetCollectionStats and DistinctValues for ttl. Caching is disabled by default.
+func WithAggregateCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.aggregateTTL = ttl
+	}
+}
+
+// CollectionCount returns the total number of items in a collection. It asks
+// for a single item's id, so next to nothing is transferred, which makes it
+// much cheaper than counting a filtered query.
+func (c *Client) CollectionCount(ctx context.Context, appID, collectionID int) (int, error) {
+	key := fmt.Sprintf("count:%d:%d", appID, collectionID)
+	if v, ok := c.aggregates.get(key, c.clock.Now()); ok {
+		return v.(int), nil
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items?pagination[pageSize]=1&fields[0]=id",
+		c.baseUrl, appID, collectionID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return 0, err
+	}
+	p, ok := c.pagination(rsp.Meta)
+	if !ok || !p.HasTotal {
+		return 0, ErrNoTotal
+	}
+	c.aggregates.set(key, p.Total, c.clock.Now(), c.aggregateTTL)
+	return p.Total, nil
+}
+
+// Exists reports whether at least one item matches the query's filters. It
+// fetches a single item's id without counting totals, so little more than
+// the request itself is transferred.
+func (q *Query) Exists(ctx context.Context) (bool, error) {
+	params := q.params()
+	params.Del("sort")
+	firstIDOnly(params)
+	params.Set("pagination[withCount]", "false")
+	_, items, err := q.fetch(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return len(items) > 0, nil
+}
+
+// First returns the first item of the query in its sort order, fetching a
+// single item without counting totals. If no item matches, the error
+// matches ErrNotFound.
+func (q *Query) First(ctx context.Context) (*Item, error) {
+	params := q.params()
+	params.Set("pagination[page]", "1")
+	params.Set("pagination[pageSize]", "1")
+	params.Set("pagination[withCount]", "false")
+	_, items, err := q.fetch(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no item matches the query", ErrNotFound)
+	}
+	return &items[0], nil
+}
+
+// Meta returns the meta block of the query's response, such as pagination
+// totals, fetching no more than the first item's id.
+func (q *Query) Meta(ctx context.Context) (map[string]interface{}, error) {
+	params := q.params()
+	params.Del("sort")
+	firstIDOnly(params)
+	rsp, _, err := q.fetch(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.Meta, nil
+}
+
+// Count returns the number of items matching the query's filters, fetching
+// no more than the first item's id. It fails with ErrNoTotal if the server reports no
+// total.
+func (q *Query) Count() (int, error) {
+	return q.CountContext(context.Background())
+}
+
+// CountContext is like Count but aborts the request when ctx is done.
+func (q *Query) CountContext(ctx context.Context) (int, error) {
+	params := q.params()
+	params.Del("sort")
+	params.Del("pagination[withCount]")
+	firstIDOnly(params)
+	rsp, _, err := q.fetch(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	p, ok := q.client.pagination(rsp.Meta)
+	if !ok || !p.HasTotal {
+		return 0, ErrNoTotal
+	}
+	return p.Total, nil
+}
+
+// firstIDOnly limits params to the id of the first item; the smallest page
+// the API accepts holds one item.
+func firstIDOnly(params url.Values) {
+	params.Set("pagination[page]", "1")
+	params.Set("pagination[pageSize]", "1")
+	for key := range params {
+		if strings.HasPrefix(key, "fields[") {
+			params.Del(key)
+		}
+	}
+	addSelectParams(params, []string{"id"})
+}
@@ -0,0 +1,42 @@
+package carthooks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestCountFetchesOneID(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	for i := 0; i < 3; i++ {
+		s.AddItem(1, 2, map[string]interface{}{"status": "open", "n": i})
+	}
+	s.AddItem(1, 2, map[string]interface{}{"status": "closed"})
+	c := s.Client()
+	ctx := context.Background()
+
+	n, err := c.Query(1, 2).Filter("status", "$eq", "open").CountContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Count = %d, want 3", n)
+	}
+	total, err := c.CollectionCount(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Errorf("CollectionCount = %d, want 4", total)
+	}
+	for _, r := range s.Requests() {
+		if got := r.Query.Get("pagination[pageSize]"); got != "1" {
+			t.Errorf("%s: pageSize = %q, want 1", r.RawQuery, got)
+		}
+		if got := r.Query.Get("fields[0]"); got != "id" {
+			t.Errorf("%s: fields[0] = %q, want id", r.RawQuery, got)
+		}
+	}
+}
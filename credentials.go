@@ -0,0 +1,69 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WithClientCredentials makes the client obtain access tokens with the
+// OAuth2 client credentials grant from the API's /oauth/token endpoint,
+// refreshing them as WithTokenSource does.
+func WithClientCredentials(clientID, clientSecret string, scopes ...string) Option {
+	return func(c *Client) {
+		c.tokens.provider = func(ctx context.Context) (*Token, error) {
+			return c.clientCredentialsToken(ctx, clientID, clientSecret, scopes)
+		}
+	}
+}
+
+// clientCredentialsToken requests a token for the client credentials grant.
+// It uses send rather than do, whose authorization would ask for a token
+// again.
+func (c *Client) clientCredentialsToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	info := RequestInfo{Method: http.MethodPost, URL: req.URL.String(), Route: routeOf(req.URL.String()), Attempt: 1}
+	resp, err := c.send(ctx, req, nil, &info)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("carthooks: decoding token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return nil, &APIError{StatusCode: resp.StatusCode, Key: result.Error, Message: result.Description, Body: body}
+	}
+	token := &Token{AccessToken: result.AccessToken}
+	if result.ExpiresIn > 0 {
+		token.Expiry = c.clock.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,23 @@
+package carthooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// GetCSV streams the query results to w as CSV rendered by the server. Filters,
+// sort and pagination apply as for Get. Errors are reported from the JSON
+// error body and nothing is written to w in that case.
+func (q *Query) GetCSV(ctx context.Context, w io.Writer) error {
+	if q.err != nil {
+		return q.err
+	}
+	resp, err := q.client.stream(ctx, http.MethodGet, q.itemsURL(q.params()), nil, http.Header{"Accept": {"text/csv"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,76 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// keysetPrefix marks the cursors the SDK makes up itself when the server has
+// no cursor pagination: the ID of the last item seen.
+const keysetPrefix = "keyset:"
+
+// ItemPage is one page of a query using cursor pagination.
+type ItemPage struct {
+	Items []Item
+	// NextCursor continues the query with Cursor; it is empty on the last
+	// page.
+	NextCursor string
+}
+
+// Cursor switches the query to cursor pagination, starting after the page
+// that returned token as its NextCursor, or at the beginning for "". Unlike
+// page numbers, cursors stay fast deep into large collections and neither
+// skip nor repeat items when items are added or removed while paging. GetAll,
+// Each and Iterate then page by cursor too.
+//
+// Servers without cursor pagination are paged by item ID instead, which
+// requires the query to be unsorted or sorted by "id" ascending only.
+func (q *Query) Cursor(token string) *Query {
+	q.cursor, q.cursorMode = token, true
+	return q
+}
+
+// GetPage fetches the page of the query at its cursor, set with Cursor; a
+// query without one starts at the beginning.
+func (q *Query) GetPage(ctx context.Context) (*ItemPage, error) {
+	items, next, err := q.fetchCursor(ctx, q.cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &ItemPage{Items: items, NextCursor: next}, nil
+}
+
+// fetchCursor fetches the page after token and returns the cursor of the
+// next one.
+func (q *Query) fetchCursor(ctx context.Context, token string) ([]Item, string, error) {
+	params := q.params()
+	params.Del("pagination[page]")
+	if len(q.sort) == 0 {
+		params.Set("sort", "id:asc")
+	}
+	if after, ok := strings.CutPrefix(token, keysetPrefix); ok {
+		params.Set("filters[id][$gt]", after)
+	} else {
+		params.Set("pagination[mode]", "cursor")
+		if token != "" {
+			params.Set("pagination[cursor]", token)
+		}
+	}
+	rsp, items, err := q.fetch(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	p, ok := q.client.pagination(rsp.Meta)
+	if ok && p.HasCursor {
+		return items, p.NextCursor, nil
+	}
+	if len(q.sort) > 0 && (len(q.sort) != 1 || (q.sort[0] != "id" && q.sort[0] != "id:asc")) {
+		return nil, "", errors.New("carthooks: server has no cursor pagination, and paging by ID needs the query sorted by id ascending")
+	}
+	if lastPage(p, ok, 1, len(items), q.limit) {
+		return items, "", nil
+	}
+	return items, keysetPrefix + strconv.Itoa(items[len(items)-1].ID), nil
+}
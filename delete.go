@@ -0,0 +1,21 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+)
+
+// DeleteItemIfExists deletes an item and reports whether this call deleted
+// it. An item that is already gone (404) yields deleted == false and a nil
+// error, so cleanup jobs can be retried safely; any other failure is
+// returned as an error. DeleteItem, by contrast, returns an error matching
+// ErrNotFound for a missing item.
+func (c *Client) DeleteItemIfExists(ctx context.Context, appID, collectionID, itemID int, opts ...WriteOption) (deleted bool, err error) {
+	if _, err := c.DeleteItemContext(ctx, appID, collectionID, itemID, opts...); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// distinctPageSize is the page size DistinctValues scans with.
+const distinctPageSize = 100
+
+// DistinctValues returns the distinct values of a field across a collection,
+// in the order they are first seen. Elements of multi-value fields count
+// individually; empty values are skipped.
+//
+// The API has no aggregation for this, so the whole collection is scanned
+// page by page, fetching only the field. That costs one request per 100
+// items; use WithAggregateCacheTTL to reuse results for repeated calls.
+func (c *Client) DistinctValues(ctx context.Context, appID, collectionID int, field string) ([]interface{}, error) {
+	key := fmt.Sprintf("distinct:%d:%d:%s", appID, collectionID, field)
+	if v, ok := c.aggregates.get(key, c.clock.Now()); ok {
+		return v.([]interface{}), nil
+	}
+	ctx = withOperation(ctx, "DistinctValues")
+	items, err := c.Query(appID, collectionID).
+		Select(field).
+		Limit(distinctPageSize).
+		WithoutCount().
+		GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+	seen := map[string]bool{}
+	add := func(v interface{}) {
+		if v == nil || v == "" {
+			return
+		}
+		b, err := json.Marshal(v)
+		if err != nil || seen[string(b)] {
+			return
+		}
+		seen[string(b)] = true
+		values = append(values, v)
+	}
+	for _, item := range items {
+		if list, ok := item.Fields[field].([]interface{}); ok {
+			for _, v := range list {
+				add(v)
+			}
+			continue
+		}
+		add(item.Fields[field])
+	}
+	c.aggregates.set(key, values, c.clock.Now(), c.aggregateTTL)
+	return values, nil
+}
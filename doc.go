@@ -0,0 +1,9 @@
+// Package carthooks is a client for the Carthooks API.
+//
+// Every call that talks to the API accepts a context.Context. Older methods
+// such as GetItemByID or Query.Get have a Context variant (GetItemByIDContext,
+// Query.GetContext) taking ctx as the first parameter, and the plain form uses
+// context.Background(); newer methods take ctx directly. Cancelling ctx or
+// letting its deadline pass aborts the in-flight request, retry waits and, for
+// paginated helpers, the remaining pages.
+package carthooks
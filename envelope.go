@@ -0,0 +1,35 @@
+package carthooks
+
+import "encoding/json"
+
+const defaultDataKey = "data"
+
+// WithDataKey sets the envelope key wrapping item payloads in requests and
+// responses, for API variants that do not use the default "data".
+func WithDataKey(key string) Option {
+	return func(c *Client) {
+		c.dataKey = key
+	}
+}
+
+// envelope wraps a request payload in the configured data key.
+func (c *Client) envelope(data interface{}) map[string]any {
+	return map[string]any{c.dataKey: data}
+}
+
+// decodeResponse decodes a response body, taking Data from the configured
+// envelope key.
+func (c *Client) decodeResponse(body []byte, result *Response) error {
+	if err := json.Unmarshal(body, result); err != nil {
+		return err
+	}
+	if c.dataKey == defaultDataKey {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+	result.Data = raw[c.dataKey]
+	return nil
+}
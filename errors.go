@@ -0,0 +1,118 @@
+package carthooks
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound matches errors for resources that do not exist (HTTP 404).
+var ErrNotFound = errors.New("carthooks: not found")
+
+// ErrInvalid matches errors for requests the API rejected as invalid (HTTP
+// 400 or 422), and ValidationError.
+var ErrInvalid = errors.New("carthooks: invalid request")
+
+// ErrNotModified is returned for a conditional read (IfNoneMatch) when the
+// resource has not changed, so the caller's cached copy is still current.
+var ErrNotModified = errors.New("carthooks: not modified")
+
+// ErrConflict matches errors caused by a failed precondition, such as an
+// If-Match version that no longer matches the stored item.
+var ErrConflict = errors.New("carthooks: conflict")
+
+// ConflictError is returned for 409 and 412 responses. It matches ErrConflict
+// with errors.Is.
+type ConflictError struct {
+	StatusCode int
+	// CurrentVersion is the item's current version (ETag) as reported by
+	// the server, if any.
+	CurrentVersion string
+
+	err *APIError
+}
+
+func (e *ConflictError) Error() string {
+	if e.CurrentVersion == "" {
+		return fmt.Sprintf("carthooks: conflict (status %d)", e.StatusCode)
+	}
+	return fmt.Sprintf("carthooks: conflict (status %d), current version %s", e.StatusCode, e.CurrentVersion)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+func (e *ConflictError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// ErrPayloadTooLarge matches errors for request bodies the server rejected
+// as too large (HTTP 413).
+var ErrPayloadTooLarge = errors.New("carthooks: payload too large")
+
+// PayloadTooLargeError is returned for 413 responses. It matches
+// ErrPayloadTooLarge with errors.Is.
+type PayloadTooLargeError struct {
+	// Limit is the maximum body size in bytes as reported by the server in
+	// the X-Max-Content-Length header, or zero if it was not reported.
+	Limit int64
+
+	err *APIError
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	if e.Limit <= 0 {
+		return ErrPayloadTooLarge.Error()
+	}
+	return fmt.Sprintf("%v (limit %d bytes)", ErrPayloadTooLarge, e.Limit)
+}
+
+func (e *PayloadTooLargeError) Is(target error) bool {
+	return target == ErrPayloadTooLarge
+}
+
+func (e *PayloadTooLargeError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// IsNotFound reports whether err means the resource does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is a version conflict (see ConflictError).
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsLocked reports whether err means the item is locked by someone else.
+func IsLocked(err error) bool {
+	return errors.Is(err, ErrLocked)
+}
+
+// IsInvalid reports whether err is a validation failure, from the API or from
+// checks the SDK makes before sending.
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrInvalid)
+}
+
+// IsRateLimited reports whether err is a 429 response.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// StatusCode returns the HTTP status of the failed request behind err, or 0
+// if err does not come from an API response.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
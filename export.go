@@ -0,0 +1,225 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Format is a file format of Export and Import.
+type Format string
+
+const (
+	// FormatJSONLines is newline-delimited JSON, one item per line.
+	FormatJSONLines Format = "jsonl"
+	// FormatCSV is comma-separated values with a header row.
+	FormatCSV Format = "csv"
+)
+
+// ExportOption customizes Export.
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	pageSize int
+	format   Format
+	query    []func(*Query)
+	columns  []string
+}
+
+// ExportPageSize sets how many items Export fetches per request.
+func ExportPageSize(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.pageSize = n
+	}
+}
+
+// ExportAs sets the format Export writes. The default is FormatJSONLines.
+func ExportAs(format Format) ExportOption {
+	return func(o *exportOptions) {
+		o.format = format
+	}
+}
+
+// ExportColumns sets the field columns FormatCSV writes after the "id"
+// column, in order. Fields not listed are left out.
+func ExportColumns(columns ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.columns = columns
+	}
+}
+
+// ExportQuery lets fn narrow or order the exported items, e.g. with Filter
+// and OrderBy, on the query Export pages through.
+func ExportQuery(fn func(*Query)) ExportOption {
+	return func(o *exportOptions) {
+		o.query = append(o.query, fn)
+	}
+}
+
+// defaultExportPageSize is the page size Export uses unless ExportPageSize is
+// given.
+const defaultExportPageSize = 100
+
+// exportedItem is the NDJSON line written for an item.
+type exportedItem struct {
+	ID     int                    `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// Export writes every item of a collection to w as newline-delimited JSON,
+// one {"id":...,"fields":{...}} object per line, or in the format set with
+// ExportAs. See Query.Export.
+func (c *Client) Export(ctx context.Context, appID, collectionID int, w io.Writer, opts ...ExportOption) error {
+	ctx = withOperation(ctx, "Export")
+	o := exportOptions{pageSize: defaultExportPageSize, format: FormatJSONLines}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	q := c.Query(appID, collectionID).Limit(o.pageSize).WithoutCount()
+	for _, fn := range o.query {
+		fn(q)
+	}
+	return q.Export(ctx, w, o.format, opts...)
+}
+
+// Export writes the items matching the query to w, fetching one page at a
+// time so memory use does not grow with the result. If w has a Flush method,
+// as *bufio.Writer does, it is called after each page. Export stops when ctx
+// is done; the items written so far stay in w.
+//
+// FormatJSONLines writes one {"id":...,"fields":{...}} object per line.
+// FormatCSV writes an "id" column followed by the columns given with
+// ExportColumns, or else one column per field of the first page in sorted
+// order; then a field that only appears on a later page fails the export,
+// since the header is already written. Lists and objects are written as
+// JSON, which Import reads back with ColumnJSON. Of opts, only ExportColumns
+// applies.
+func (q *Query) Export(ctx context.Context, w io.Writer, format Format, opts ...ExportOption) error {
+	var o exportOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var write func([]Item) error
+	switch format {
+	case FormatJSONLines:
+		enc := json.NewEncoder(w)
+		write = func(items []Item) error {
+			for _, item := range items {
+				if err := enc.Encode(exportedItem{ID: item.ID, Fields: item.Fields}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		columns := o.columns
+		// inferred holds the columns taken from the first page, if not
+		// given.
+		var inferred map[string]bool
+		header := false
+		write = func(items []Item) error {
+			if !header {
+				if columns == nil {
+					columns = FieldNames(items)
+					inferred = map[string]bool{"id": true}
+					for _, name := range columns {
+						inferred[name] = true
+					}
+				}
+				if err := cw.Write(append([]string{"id"}, columns...)); err != nil {
+					return err
+				}
+				header = true
+			}
+			record := make([]string, len(columns)+1)
+			for _, item := range items {
+				if inferred != nil {
+					if name, ok := unknownField(item.Fields, inferred); ok {
+						return fmt.Errorf("carthooks: item %d has field %q, which is not among the CSV columns taken from the first page; list the columns with ExportColumns", item.ID, name)
+					}
+				}
+				record[0] = strconv.Itoa(item.ID)
+				for i, name := range columns {
+					v, err := FormatValue(item.Fields[name])
+					if err != nil {
+						return fmt.Errorf("carthooks: item %d field %q: %w", item.ID, name, err)
+					}
+					record[i+1] = v
+				}
+				if err := cw.Write(record); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return fmt.Errorf("carthooks: unknown format %q", format)
+	}
+	flusher, _ := w.(interface{ Flush() error })
+	return q.eachPage(ctx, func(items []Item) error {
+		if err := write(items); err != nil {
+			return err
+		}
+		if flusher != nil {
+			return flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// FieldNames returns the sorted names of the fields of items, leaving out
+// "id", which has a column of its own in exports. They are the CSV columns
+// Export writes unless ExportColumns is given.
+func FieldNames(items []Item) []string {
+	seen := map[string]bool{"id": true}
+	columns := []string{}
+	for _, item := range items {
+		for name := range item.Fields {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// unknownField returns a field of fields that is not in known.
+func unknownField(fields map[string]interface{}, known map[string]bool) (string, bool) {
+	for name := range fields {
+		if !known[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// FormatValue renders a field value as text the way Export writes CSV cells:
+// strings as they are, empty values as an empty string, and anything else as
+// JSON without the quotes of a JSON string.
+func FormatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s, nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,162 @@
+package carthooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []carthooks.Format{carthooks.FormatJSONLines, carthooks.FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			s := carthookstest.NewServer()
+			defer s.Close()
+			for _, title := range []string{"a", "b", "c"} {
+				s.AddItem(1, 2, map[string]interface{}{"title": title, "updatedAt": "2026-01-02T00:00:00Z"})
+			}
+			c := s.Client()
+			ctx := context.Background()
+
+			var buf bytes.Buffer
+			if err := c.Export(ctx, 1, 2, &buf, carthooks.ExportAs(format), carthooks.ExportPageSize(2)); err != nil {
+				t.Fatal(err)
+			}
+			res, err := c.Import(ctx, 1, 3, &buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Rows != 3 || len(res.Created) != 3 {
+				t.Fatalf("got %d rows and %d created, want 3", res.Rows, len(res.Created))
+			}
+			for _, r := range s.Requests() {
+				if r.Method == http.MethodPost && bytes.Contains(r.Body, []byte("updatedAt")) {
+					t.Errorf("import sent updatedAt: %s", r.Body)
+				}
+			}
+			var titles []string
+			for _, item := range s.Items(1, 3) {
+				titles = append(titles, item.Fields["title"].(string))
+			}
+			// Import creates the items of a batch concurrently.
+			sort.Strings(titles)
+			if want := []string{"a", "b", "c"}; !reflect.DeepEqual(titles, want) {
+				t.Errorf("got titles %v, want %v", titles, want)
+			}
+		})
+	}
+}
+
+func TestExportCSVLateField(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	s.AddItem(1, 2, map[string]interface{}{"title": "b", "notes": "x, y"})
+	c := s.Client()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	err := c.Export(ctx, 1, 2, &buf, carthooks.ExportAs(carthooks.FormatCSV), carthooks.ExportPageSize(1))
+	if err == nil || !strings.Contains(err.Error(), `"notes"`) {
+		t.Errorf("got error %v, want one naming the notes field", err)
+	}
+
+	buf.Reset()
+	err = c.Export(ctx, 1, 2, &buf, carthooks.ExportAs(carthooks.FormatCSV), carthooks.ExportPageSize(1),
+		carthooks.ExportColumns("title", "notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,title,notes\n1,a,\n2,b,\"x, y\"\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+// pagedItemsServer serves total generated items, page by page, without
+// keeping them, and counts the pages served.
+func pagedItemsServer(total int) (*httptest.Server, *int32) {
+	var pages int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pages, 1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("pagination[page]"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("pagination[pageSize]"))
+		var buf bytes.Buffer
+		buf.WriteString(`{"data":[`)
+		for id := (page-1)*size + 1; id <= page*size && id <= total; id++ {
+			if id > (page-1)*size+1 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(&buf, `{"id":%d,"fields":{"title":"item %d"}}`, id, id)
+		}
+		buf.WriteString(`]}`)
+		w.Write(buf.Bytes())
+	})), &pages
+}
+
+// lineCounter counts the lines written to it and discards them.
+type lineCounter struct {
+	lines, flushes int
+	onFlush        func()
+}
+
+func (l *lineCounter) Write(p []byte) (int, error) {
+	l.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func (l *lineCounter) Flush() error {
+	l.flushes++
+	if l.onFlush != nil {
+		l.onFlush()
+	}
+	return nil
+}
+
+func TestExportLargeCollection(t *testing.T) {
+	s, pages := pagedItemsServer(5000)
+	defer s.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+
+	var w lineCounter
+	if err := c.Export(context.Background(), 1, 2, &w, carthooks.ExportPageSize(250)); err != nil {
+		t.Fatal(err)
+	}
+	if w.lines != 5000 {
+		t.Errorf("got %d lines, want 5000", w.lines)
+	}
+	// 20 full pages and the empty one that ends the export.
+	if got := atomic.LoadInt32(pages); got != 21 {
+		t.Errorf("fetched %d pages, want 21", got)
+	}
+	if w.flushes != 21 {
+		t.Errorf("flushed %d times, want once per page", w.flushes)
+	}
+}
+
+func TestExportStopsWhenCanceled(t *testing.T) {
+	s, pages := pagedItemsServer(5000)
+	defer s.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := lineCounter{onFlush: func() { cancel() }}
+	err := c.Export(ctx, 1, 2, &w, carthooks.ExportPageSize(250))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	if w.lines != 250 || atomic.LoadInt32(pages) != 1 {
+		t.Errorf("got %d lines from %d pages, want the first page only", w.lines, atomic.LoadInt32(pages))
+	}
+}
@@ -0,0 +1,48 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// FieldOption is one choice of a select field. Cascading select fields nest
+// their dependent choices under Children.
+type FieldOption struct {
+	ID       string        `json:"id"`
+	Label    string        `json:"label"`
+	Color    string        `json:"color,omitempty"`
+	ParentID string        `json:"parent_id,omitempty"`
+	Children []FieldOption `json:"children,omitempty"`
+}
+
+// WithSchemaCacheTTL caches schema lookups such as GetFieldOptions for ttl.
+// By default GetFieldOptions is not cached, and ValidateItemData keeps a
+// collection's schema for a minute. AddField, UpdateField and DeleteField
+// drop the cached schema of their collection.
+func WithSchemaCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.schemaTTL = ttl
+	}
+}
+
+// GetFieldOptions returns the valid options of a select field.
+func (c *Client) GetFieldOptions(ctx context.Context, appID, collectionID int, fieldName string) ([]FieldOption, error) {
+	key := fmt.Sprintf("options:%d:%d:%s", appID, collectionID, fieldName)
+	if v, ok := c.schema.get(key, c.clock.Now()); ok {
+		return v.([]FieldOption), nil
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/fields/%s/options",
+		c.baseUrl, appID, collectionID, url.PathEscape(fieldName))
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	options := []FieldOption{}
+	if err := rsp.Bind(&options); err != nil {
+		return nil, err
+	}
+	c.schema.set(key, options, c.clock.Now(), c.schemaTTL)
+	return options, nil
+}
@@ -0,0 +1,60 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestFieldChangesDropCachedOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		change func(c *carthooks.Client) error
+	}{
+		{"UpdateField", func(c *carthooks.Client) error {
+			_, err := c.UpdateField(context.Background(), 1, 2, "status", carthooks.FieldDefinition{Name: "status"})
+			return err
+		}},
+		{"DeleteField", func(c *carthooks.Client) error {
+			return c.DeleteField(context.Background(), 1, 2, "status")
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fetches++
+					io.WriteString(w, `{"data":[{"id":"1","label":"open"}]}`)
+					return
+				}
+				io.WriteString(w, `{"data":{"name":"status"}}`)
+			}))
+			defer s.Close()
+			c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL), carthooks.WithSchemaCacheTTL(time.Hour))
+			ctx := context.Background()
+
+			for i := 0; i < 2; i++ {
+				if _, err := c.GetFieldOptions(ctx, 1, 2, "status"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if fetches != 1 {
+				t.Fatalf("%d fetches before the change, want 1", fetches)
+			}
+			if err := tt.change(c); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetFieldOptions(ctx, 1, 2, "status"); err != nil {
+				t.Fatal(err)
+			}
+			if fetches != 2 {
+				t.Errorf("%d fetches after the change, want 2", fetches)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package carthooks
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// conditions is one branch of an OR group: filters ANDed together, plus any
+// nested OR groups.
+type conditions struct {
+	filters []filter
+	or      [][]conditions
+}
+
+// FilterValues adds a condition with a multi-value operator such as $in,
+// $notIn or $between. The values are sent as an indexed list:
+//
+//	filters[status][$in][0]=open&filters[status][$in][1]=pending
+//
+// Like Filter, it replaces an earlier condition on the same field and
+// operator.
+func (q *Query) FilterValues(field, operator string, values []string) *Query {
+	values = append([]string{}, values...)
+	for i, f := range q.filters {
+		if f.field == field && f.operator == operator {
+			q.filters[i].value, q.filters[i].values = "", values
+			return q
+		}
+	}
+	q.filters = append(q.filters, filter{field: field, operator: operator, values: values})
+	return q
+}
+
+// FilterIn restricts field to one of values, using $in.
+func (q *Query) FilterIn(field string, values []string) *Query {
+	return q.FilterValues(field, "$in", values)
+}
+
+// Or adds a group of alternatives: an item matches the group if it matches
+// any branch, and a branch matches if it matches all the conditions added to
+// it. Each branch function receives an empty query to add conditions to with
+// Filter, FilterValues or a nested Or; other settings on it are ignored.
+//
+// The group is ANDed with the query's other conditions. A single group is
+// sent under $or, with one index per branch:
+//
+//	filters[$or][0][status][$eq]=A&filters[$or][1][status][$eq]=B
+//
+// Several groups are combined under $and, as filters[$and][0][$or][0]...
+func (q *Query) Or(branches ...func(*Query)) *Query {
+	group := make([]conditions, 0, len(branches))
+	for _, branch := range branches {
+		b := &Query{client: q.client, appID: q.appID, collectionID: q.collectionID}
+		branch(b)
+		if b.err != nil && q.err == nil {
+			q.err = b.err
+		}
+		group = append(group, conditions{filters: b.filters, or: b.or})
+	}
+	q.or = append(q.or, group)
+	return q
+}
+
+// addFilterParams serializes filters and OR groups under prefix.
+func addFilterParams(params url.Values, prefix string, filters []filter, or [][]conditions) {
+	for _, f := range filters {
+		key := prefix + "[" + f.field + "][" + f.operator + "]"
+		if f.values == nil {
+			params.Add(key, f.value)
+			continue
+		}
+		for i, v := range f.values {
+			params.Add(key+"["+strconv.Itoa(i)+"]", v)
+		}
+	}
+	if len(or) == 1 {
+		addOrParams(params, prefix+"[$or]", or[0])
+		return
+	}
+	for g, group := range or {
+		addOrParams(params, prefix+"[$and]["+strconv.Itoa(g)+"][$or]", group)
+	}
+}
+
+func addOrParams(params url.Values, prefix string, branches []conditions) {
+	for i, b := range branches {
+		addFilterParams(params, prefix+"["+strconv.Itoa(i)+"]", b.filters, b.or)
+	}
+}
@@ -0,0 +1,117 @@
+package carthooks
+
+import (
+	"context"
+	"sync"
+)
+
+// QueryGroup runs queries and other calls concurrently on a bounded number
+// of workers, in the manner of errgroup. Create one with Client.Parallel,
+// add work with Query, GetItem or Go, then call Wait:
+//
+//	g := c.Parallel(ctx, 4)
+//	orders := g.Query(c.Query(appID, ordersID).Filter("status", OpEq, "open"))
+//	customer := g.GetItem(appID, customersID, customerID)
+//	if err := g.Wait(); err != nil {
+//		...
+//	}
+//	use(orders.Items, customer.Item)
+//
+// The first failure cancels the context of the calls still running or
+// waiting and is returned by Wait. Results must not be read before Wait
+// returns.
+type QueryGroup struct {
+	c      *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// QueryResult is the outcome of a query run by a QueryGroup.
+type QueryResult struct {
+	Items []Item
+	Err   error
+}
+
+// ItemResult is the outcome of an item read run by a QueryGroup.
+type ItemResult struct {
+	Item *Item
+	Err  error
+}
+
+// Parallel returns a QueryGroup running at most concurrency calls at a time
+// (DefaultConcurrency if zero or less).
+func (c *Client) Parallel(ctx context.Context, concurrency int) *QueryGroup {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	ctx, cancel := context.WithCancel(withOperation(ctx, "Parallel"))
+	return &QueryGroup{c: c, ctx: ctx, cancel: cancel, sem: make(chan struct{}, concurrency)}
+}
+
+// Go runs fn with the group's context, blocking while all workers are busy.
+// Each call first waits out any Retry-After the API asked for.
+func (g *QueryGroup) Go(fn func(ctx context.Context) error) {
+	g.start(fn, func(error) {})
+}
+
+// start runs fn like Go, calling skipped instead if fn cannot be started.
+func (g *QueryGroup) start(fn func(ctx context.Context) error, skipped func(error)) {
+	select {
+	case g.sem <- struct{}{}:
+	case <-g.ctx.Done():
+		skipped(g.ctx.Err())
+		g.fail(g.ctx.Err())
+		return
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() { <-g.sem }()
+		if err := g.c.waitRetryAfter(g.ctx); err != nil {
+			skipped(err)
+			g.fail(err)
+			return
+		}
+		if err := fn(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Query runs one page of q, as GetContext does.
+func (g *QueryGroup) Query(q *Query) *QueryResult {
+	r := &QueryResult{}
+	g.start(func(ctx context.Context) error {
+		r.Items, r.Err = q.GetContext(ctx)
+		return r.Err
+	}, func(err error) { r.Err = err })
+	return r
+}
+
+// GetItem reads an item, as GetItemByIDContext does.
+func (g *QueryGroup) GetItem(appID, collectionID, itemID int, opts ...ItemOption) *ItemResult {
+	r := &ItemResult{}
+	g.start(func(ctx context.Context) error {
+		r.Item, r.Err = g.c.GetItemByIDContext(ctx, appID, collectionID, itemID, opts...)
+		return r.Err
+	}, func(err error) { r.Err = err })
+	return r
+}
+
+// Wait waits for all calls to finish and returns the first error, if any.
+func (g *QueryGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+func (g *QueryGroup) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
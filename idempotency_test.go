@@ -0,0 +1,77 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestIdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		opts    []carthooks.Option
+		create  func(c *carthooks.Client) error
+		wantKey string
+		// drop fails the first attempt by closing the connection, as a
+		// timeout would, instead of answering 503.
+		drop bool
+	}{
+		{"CreateItemWithKey", nil, func(c *carthooks.Client) error {
+			_, err := c.CreateItemWithKey(context.Background(), 1, 2, map[string]interface{}{"title": "a"}, "key-1")
+			return err
+		}, "key-1", false},
+		{"WithIdempotencyKeys", []carthooks.Option{carthooks.WithIdempotencyKeys()}, func(c *carthooks.Client) error {
+			_, err := c.CreateItem(1, 2, map[string]interface{}{"title": "a"})
+			return err
+		}, "", false},
+		{"WithIdempotencyKey", nil, func(c *carthooks.Client) error {
+			_, _, err := c.CreateItemWithResponse(context.Background(), 1, 2, map[string]interface{}{"title": "a"},
+				carthooks.WithIdempotencyKey("key-2"))
+			return err
+		}, "key-2", false},
+		{"WithIdempotencyKey after a network error", nil, func(c *carthooks.Client) error {
+			_, _, err := c.CreateItemWithResponse(context.Background(), 1, 2, map[string]interface{}{"title": "a"},
+				carthooks.WithIdempotencyKey("key-3"))
+			return err
+		}, "key-3", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				keys = append(keys, r.Header.Get("Idempotency-Key"))
+				if len(keys) == 1 && tt.drop {
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+					return
+				}
+				if len(keys) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					io.WriteString(w, `{"error":{"key":"ERROR_UNAVAILABLE"}}`)
+					return
+				}
+				io.WriteString(w, `{"data":{"id":1,"fields":{"title":"a"}}}`)
+			}))
+			defer s.Close()
+			opts := append([]carthooks.Option{carthooks.WithBaseURL(s.URL), carthooks.WithClock(newFakeClock()),
+				carthooks.WithRetry(2, 0)}, tt.opts...)
+			c := carthooks.NewClient("token", opts...)
+
+			if err := tt.create(c); err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != 2 {
+				t.Fatalf("%d attempts, want 2", len(keys))
+			}
+			if keys[0] == "" || keys[0] != keys[1] {
+				t.Errorf("keys = %q, want the same key on both attempts", keys)
+			}
+			if tt.wantKey != "" && keys[0] != tt.wantKey {
+				t.Errorf("key = %q, want %q", keys[0], tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package carthooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ColumnType tells Import how to convert the text of a column.
+type ColumnType int
+
+const (
+	// ColumnString keeps the text as it is.
+	ColumnString ColumnType = iota
+	// ColumnNumber parses the text as a number.
+	ColumnNumber
+	// ColumnBool parses the text as a boolean, as strconv.ParseBool does.
+	ColumnBool
+	// ColumnList splits the text on commas into a list of strings.
+	ColumnList
+	// ColumnJSON parses the text as JSON, e.g. a list or an object written
+	// by Export.
+	ColumnJSON
+)
+
+// ImportOption customizes Import.
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	columns   map[string]string
+	types     map[string]ColumnType
+	batchSize int
+	batch     []BatchOption
+}
+
+// ImportColumns maps column names, or keys of JSON lines, to field names.
+// Columns missing from columns are imported under their own name; columns
+// mapped to "" are skipped.
+func ImportColumns(columns map[string]string) ImportOption {
+	return func(o *importOptions) {
+		o.columns = columns
+	}
+}
+
+// ImportTypes sets how the text of fields is converted, by field name.
+// Fields not listed are imported as strings. Only string values are
+// converted, so the types apply to every CSV column but only to the JSON
+// values that are strings.
+func ImportTypes(types map[string]ColumnType) ImportOption {
+	return func(o *importOptions) {
+		o.types = types
+	}
+}
+
+// ImportBatchSize sets how many rows are read before their items are created,
+// DefaultChunkSize by default. opts tune the BatchCreateItems of each batch.
+func ImportBatchSize(n int, opts ...BatchOption) ImportOption {
+	return func(o *importOptions) {
+		o.batchSize = n
+		o.batch = opts
+	}
+}
+
+// RowErrors maps the line number of each input row that could not be
+// imported to its error.
+type RowErrors map[int]error
+
+func (e RowErrors) Error() string {
+	rows := make([]int, 0, len(e))
+	for row := range e {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	msgs := make([]string, len(rows))
+	for n, row := range rows {
+		msgs[n] = fmt.Sprintf("line %d: %v", row, e[row])
+	}
+	return fmt.Sprintf("carthooks: %d row(s) not imported: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ImportResult reports the outcome of Import.
+type ImportResult struct {
+	// Rows is the number of rows read, blank lines and the CSV header
+	// aside.
+	Rows    int
+	Created []*Item
+	Failed  RowErrors
+}
+
+// Import creates an item for each row read from r, in batches created with
+// BatchCreateItems. FormatCSV expects a header row naming the columns;
+// FormatJSONLines expects one object per line, either the fields themselves
+// or an {"id":...,"fields":{...}} object as written by Export. System fields
+// such as "id" and "updatedAt" are ignored, since the items are created anew,
+// and empty CSV cells leave their field unset.
+//
+// Rows that cannot be parsed, converted or created are reported in
+// ImportResult.Failed by line number and do not stop the import; the error
+// is then that RowErrors. Import stops early when r cannot be read or ctx is
+// done, returning what was imported so far.
+func (c *Client) Import(ctx context.Context, appID, collectionID int, r io.Reader, format Format, opts ...ImportOption) (ImportResult, error) {
+	ctx = withOperation(ctx, "Import")
+	o := importOptions{batchSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultChunkSize
+	}
+	var next func() (line int, fields map[string]interface{}, err error)
+	switch format {
+	case FormatCSV:
+		next = csvRows(r)
+	case FormatJSONLines:
+		next = jsonRows(r)
+	default:
+		return ImportResult{}, fmt.Errorf("carthooks: unknown format %q", format)
+	}
+
+	result := ImportResult{Failed: RowErrors{}}
+	var (
+		lines []int
+		batch []map[string]interface{}
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		items, err := c.BatchCreateItems(ctx, appID, collectionID, batch, append([]BatchOption{WithChunkSize(len(batch))}, o.batch...)...)
+		var createErrs CreateErrors
+		if err != nil && !errors.As(err, &createErrs) {
+			return err
+		}
+		for i, item := range items {
+			if item != nil {
+				result.Created = append(result.Created, item)
+			} else if err, ok := createErrs[i]; ok {
+				result.Failed[lines[i]] = err
+			}
+		}
+		lines, batch = lines[:0], batch[:0]
+		return nil
+	}
+	for {
+		line, fields, err := next()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			result.Rows++
+			result.Failed[line] = rowErr.err
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Rows++
+		if fields, err = o.convert(fields); err != nil {
+			result.Failed[line] = err
+			continue
+		}
+		lines, batch = append(lines, line), append(batch, fields)
+		if len(batch) == o.batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	if len(result.Failed) > 0 {
+		return result, result.Failed
+	}
+	return result, nil
+}
+
+// rowError marks an error confined to one row of the input.
+type rowError struct {
+	err error
+}
+
+func (e *rowError) Error() string {
+	return e.err.Error()
+}
+
+// convert renames the fields of a row and converts their values.
+func (o *importOptions) convert(row map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		name := column
+		if mapped, ok := o.columns[column]; ok {
+			name = mapped
+		}
+		if name == "" || IsSystemField(name) {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			v, err := convertColumn(s, o.types[name])
+			if err != nil {
+				return nil, fmt.Errorf("carthooks: column %q: %w", column, err)
+			}
+			value = v
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+func convertColumn(s string, t ColumnType) (interface{}, error) {
+	switch t {
+	case ColumnNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return n, nil
+	case ColumnBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", s)
+		}
+		return b, nil
+	case ColumnList:
+		values := []string{}
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	case ColumnJSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("%q is not JSON: %w", s, err)
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+// csvRows reads the rows of a CSV file after its header row.
+func csvRows(r io.Reader) func() (int, map[string]interface{}, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	var header []string
+	return func() (int, map[string]interface{}, error) {
+		if header == nil {
+			h, err := cr.Read()
+			if err != nil {
+				return 0, nil, err
+			}
+			if len(h) > 0 {
+				// Spreadsheet programs often start the file with a
+				// byte order mark.
+				h[0] = strings.TrimPrefix(h[0], "\ufeff")
+			}
+			header = h
+		}
+		record, err := cr.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, nil, &rowError{fmt.Errorf("carthooks: %w", err)}
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) != len(header) {
+			return line, nil, &rowError{fmt.Errorf("carthooks: row has %d columns, header has %d", len(record), len(header))}
+		}
+		fields := make(map[string]interface{}, len(record))
+		for i, v := range record {
+			if v != "" {
+				fields[header[i]] = v
+			}
+		}
+		return line, fields, nil
+	}
+}
+
+// jsonRows reads one JSON object per line, skipping blank lines.
+func jsonRows(r io.Reader) func() (int, map[string]interface{}, error) {
+	br := bufio.NewReader(r)
+	line := 0
+	return func() (int, map[string]interface{}, error) {
+		for {
+			data, err := br.ReadBytes('\n')
+			if len(data) == 0 && err != nil {
+				return 0, nil, err
+			}
+			line++
+			if data = bytes.TrimSpace(data); len(data) == 0 {
+				continue
+			}
+			var row map[string]interface{}
+			if err := json.Unmarshal(data, &row); err != nil {
+				return line, nil, &rowError{fmt.Errorf("carthooks: %w", err)}
+			}
+			if fields, ok := row["fields"].(map[string]interface{}); ok {
+				row = fields
+			}
+			return line, row, nil
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package carthooks
+
+import "net/http"
+
+// WithRequestInterceptor registers fn to be called with every fully built
+// outbound request just before it is sent, e.g. to add headers. Interceptors
+// run in registration order; an error from any of them aborts the request
+// and is returned to the caller.
+func WithRequestInterceptor(fn func(*http.Request) error) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, fn)
+	}
+}
+
+func (c *Client) intercept(req *http.Request) error {
+	for _, fn := range c.interceptors {
+		if err := fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
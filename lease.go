@@ -0,0 +1,170 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ItemLock is a lock on an item that is renewed in the background until it
+// is released. Acquire one with AcquireLock, or use WithLock.
+type ItemLock struct {
+	c                           *Client
+	appID, collectionID, itemID int
+	id                          string
+	timeout                     int
+
+	stop     chan struct{}
+	done     chan struct{}
+	lost     chan struct{}
+	mu       sync.Mutex
+	err      error
+	released bool
+}
+
+// AcquireLock locks the item for timeout seconds and keeps renewing the lock
+// with RefreshLock at a third of the timeout until Release is called or ctx
+// is done, in which case it is released as well. If the item is locked by
+// someone else it returns an error wrapping ErrLocked.
+//
+// If a renewal is refused, or none succeeds before the lock expires, the lock
+// is lost: Lost is closed and Err reports why.
+func (c *Client) AcquireLock(ctx context.Context, appID, collectionID, itemID, timeout int) (*ItemLock, error) {
+	if timeout <= 0 {
+		return nil, errors.New("carthooks: lock timeout must be positive")
+	}
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.LockItemContext(ctx, appID, collectionID, itemID, timeout, id, lockSubject); err != nil {
+		return nil, lockedError(err)
+	}
+	l := &ItemLock{
+		c: c, appID: appID, collectionID: collectionID, itemID: itemID,
+		id: id, timeout: timeout,
+		stop: make(chan struct{}), done: make(chan struct{}), lost: make(chan struct{}),
+	}
+	go l.renew(ctx, c.clock.Now().Add(time.Duration(timeout)*time.Second))
+	return l, nil
+}
+
+// ID returns the lock ID, as passed to UpdateItem and the other lock-aware
+// calls.
+func (l *ItemLock) ID() string {
+	return l.id
+}
+
+// Lost is closed when the lock was lost before being released.
+func (l *ItemLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Err returns why the lock was lost, or nil while it is held.
+func (l *ItemLock) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Release stops the renewal and unlocks the item. It is safe to call more
+// than once; later calls return nil. Releasing a lost lock only stops the
+// renewal and returns the error that lost it.
+func (l *ItemLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	l.mu.Unlock()
+	close(l.stop)
+	<-l.done
+	if err := l.Err(); err != nil {
+		return err
+	}
+	_, err := l.c.UnlockItemContext(ctx, l.appID, l.collectionID, l.itemID, l.id)
+	return err
+}
+
+func (l *ItemLock) renew(ctx context.Context, expires time.Time) {
+	defer close(l.done)
+	interval := time.Duration(l.timeout) * time.Second / 3
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ctx.Done():
+			go l.releaseDetached(ctx)
+			return
+		case <-l.c.clock.After(interval):
+		}
+		_, err := l.c.RefreshLock(ctx, l.appID, l.collectionID, l.itemID, l.id, l.timeout)
+		now := l.c.clock.Now()
+		switch {
+		case err == nil:
+			expires = now.Add(time.Duration(l.timeout) * time.Second)
+		case errors.Is(err, ErrLockLost) || !now.Before(expires):
+			if !errors.Is(err, ErrLockLost) {
+				err = fmt.Errorf("%w: expired before renewal: %v", ErrLockLost, err)
+			}
+			l.mu.Lock()
+			l.err = err
+			l.mu.Unlock()
+			close(l.lost)
+			return
+		default:
+			l.c.logger.Warn("carthooks: lock renewal failed", "item", l.itemID, "error", err)
+		}
+	}
+}
+
+// releaseDetached releases the lock after its context is done.
+func (l *ItemLock) releaseDetached(ctx context.Context) {
+	<-l.done
+	releaseCtx, cancel := context.WithTimeout(detach(ctx), 30*time.Second)
+	defer cancel()
+	if err := l.Release(releaseCtx); err != nil {
+		l.c.logger.Warn("carthooks: releasing lock failed", "item", l.itemID, "error", err)
+	}
+}
+
+// WithLock runs fn while holding a renewed lock on the item, as acquired by
+// AcquireLock, and releases the lock afterwards. The context passed to fn is
+// cancelled if the lock is lost, and WithLock then returns an error wrapping
+// ErrLockLost unless fn failed.
+func (c *Client) WithLock(ctx context.Context, appID, collectionID, itemID, timeout int, fn func(ctx context.Context) error) (err error) {
+	ctx = withOperation(ctx, "WithLock")
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	l, err := c.AcquireLock(lockCtx, appID, collectionID, itemID, timeout)
+	if err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel()
+		case <-lockCtx.Done():
+		}
+	}()
+	defer func() {
+		releaseCtx, cancelRelease := context.WithTimeout(detach(ctx), 30*time.Second)
+		defer cancelRelease()
+		if rerr := l.Release(releaseCtx); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	return fn(lockCtx)
+}
+
+// lockedError maps the API's item-locked error to ErrLocked.
+func lockedError(err error) error {
+	var rerr *ResponseError
+	if errors.As(err, &rerr) && lockedKeys[rerr.Key] {
+		return fmt.Errorf("%w: %s", ErrLocked, rerr.Key)
+	}
+	return err
+}
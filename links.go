@@ -0,0 +1,85 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrNoNextLink is returned by FollowNext when the response has no next link.
+var ErrNoNextLink = errors.New("carthooks: response has no next link")
+
+// NextLink returns the URL of the next page advertised by the server, either
+// as meta.links.next or as a Link header with rel="next". Relative links are
+// resolved against the client's base URL.
+func (r *Response) NextLink() (string, bool) {
+	return r.link("next")
+}
+
+// PrevLink is the counterpart of NextLink for the previous page.
+func (r *Response) PrevLink() (string, bool) {
+	return r.link("prev")
+}
+
+// FollowNext fetches the page NextLink points to.
+func (r *Response) FollowNext(ctx context.Context) (*Response, error) {
+	next, ok := r.NextLink()
+	if !ok || r.client == nil {
+		return nil, ErrNoNextLink
+	}
+	return r.client.GetContext(ctx, next)
+}
+
+func (r *Response) link(rel string) (string, bool) {
+	if links, ok := r.Meta["links"].(map[string]interface{}); ok {
+		if s, ok := links[rel].(string); ok && s != "" {
+			return r.resolve(s), true
+		}
+	}
+	for _, header := range r.Header.Values("Link") {
+		if s, ok := parseLinkHeader(header, rel); ok {
+			return r.resolve(s), true
+		}
+	}
+	return "", false
+}
+
+func (r *Response) resolve(link string) string {
+	if r.client == nil {
+		return link
+	}
+	base, err := url.Parse(r.client.baseUrl)
+	if err != nil {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// parseLinkHeader extracts the target of rel from an RFC 8288 Link header
+// such as `<https://x/p2>; rel="next", <https://x/p0>; rel="prev"`.
+func parseLinkHeader(header, rel string) (string, bool) {
+	for _, part := range strings.Split(header, ",") {
+		segments := strings.Split(part, ";")
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range segments[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(key, "rel") {
+				continue
+			}
+			for _, r := range strings.Fields(strings.Trim(value, `"`)) {
+				if strings.EqualFold(r, rel) {
+					return target[1 : len(target)-1], true
+				}
+			}
+		}
+	}
+	return "", false
+}
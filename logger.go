@@ -0,0 +1,117 @@
+package carthooks
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Logger receives the SDK's diagnostic output. Arguments after msg are
+// alternating key/value pairs. The method set matches *slog.Logger, so a slog
+// logger can be passed to WithLogger as is.
+//
+// The SDK logs request details at debug, rate-limit waits at info and
+// retries at warn.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// WithLogger sets the logger used by the client. By default nothing is logged.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = NopLogger{}
+		}
+		c.logger = logger
+	}
+}
+
+// NopLogger discards everything. It is the default logger.
+type NopLogger struct{}
+
+func (NopLogger) Debug(string, ...any) {}
+func (NopLogger) Info(string, ...any)  {}
+func (NopLogger) Warn(string, ...any)  {}
+func (NopLogger) Error(string, ...any) {}
+
+// Level is a logging severity. The values match those of slog.Level.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error", case-insensitively,
+// so the level can come from configuration.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("carthooks: unknown log level %q", s)
+}
+
+// NewLevelLogger returns a Logger writing one line per message to w, dropping
+// messages below min.
+func NewLevelLogger(w io.Writer, min Level) Logger {
+	return &levelLogger{out: log.New(w, "", log.LstdFlags), min: min}
+}
+
+type levelLogger struct {
+	mu  sync.Mutex
+	out *log.Logger
+	min Level
+}
+
+func (l *levelLogger) Debug(msg string, args ...any) { l.log(LevelDebug, msg, args) }
+func (l *levelLogger) Info(msg string, args ...any)  { l.log(LevelInfo, msg, args) }
+func (l *levelLogger) Warn(msg string, args ...any)  { l.log(LevelWarn, msg, args) }
+func (l *levelLogger) Error(msg string, args ...any) { l.log(LevelError, msg, args) }
+
+func (l *levelLogger) log(level Level, msg string, args []any) {
+	if level < l.min {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Print(b.String())
+}
@@ -0,0 +1,371 @@
+// Package metrics exports the request metrics of Carthooks clients in the
+// Prometheus text format, without depending on the Prometheus client
+// library.
+//
+//	m := metrics.New()
+//	client := carthooks.NewClient(token, m.Instrument("default"))
+//	http.Handle("/metrics", m)
+//
+// Requests are labeled by method and route, the URL path with its IDs
+// replaced by ":id", so the number of series stays bounded. Metrics keeps a
+// reference to every instrumented client; call Unregister when one is no
+// longer used.
+//
+// Metrics does not depend on the Prometheus client library, so it is not a
+// prometheus.Collector itself. To serve its metrics from an existing
+// registry, next to the application's own, wrap Collect in one:
+//
+//	type collector struct{ m *metrics.Metrics }
+//
+//	// Describe sends nothing, which makes the collector unchecked.
+//	func (collector) Describe(chan<- *prometheus.Desc) {}
+//
+//	func (c collector) Collect(ch chan<- prometheus.Metric) {
+//		for _, f := range c.m.Collect() {
+//			desc := prometheus.NewDesc(f.Name, f.Help, f.LabelNames, nil)
+//			for _, s := range f.Samples {
+//				switch f.Type {
+//				case metrics.Histogram:
+//					ch <- prometheus.MustNewConstHistogram(desc, s.Count, s.Sum, s.Buckets, s.LabelValues...)
+//				case metrics.Gauge:
+//					ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, s.Value, s.LabelValues...)
+//				default:
+//					ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, s.Value, s.LabelValues...)
+//				}
+//			}
+//		}
+//	}
+//
+//	prometheus.MustRegister(collector{m})
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram
+// buckets.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects the metrics of the clients it instruments:
+//
+//   - carthooks_requests_total{client,method,route,status}
+//   - carthooks_request_errors_total{client,method,route,status,key}
+//   - carthooks_request_duration_seconds{client,method,route}, a histogram
+//   - carthooks_retries_total{client,method,route}
+//   - carthooks_rate_limit_remaining{client}
+//
+// status is the HTTP status code, or 0 when no response arrived, and key
+// the error key of the API's error response.
+type Metrics struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[labels]float64
+	errors    map[labels]float64
+	retries   map[labels]float64
+	durations map[labels]*histogram
+	clients   map[string][]*carthooks.Client
+}
+
+type labels struct {
+	client, method, route, status, key string
+}
+
+type histogram struct {
+	counts []float64
+	sum    float64
+	count  float64
+}
+
+// New returns an empty Metrics using DefaultBuckets.
+func New() *Metrics {
+	return &Metrics{
+		buckets:   DefaultBuckets,
+		requests:  map[labels]float64{},
+		errors:    map[labels]float64{},
+		retries:   map[labels]float64{},
+		durations: map[labels]*histogram{},
+		clients:   map[string][]*carthooks.Client{},
+	}
+}
+
+// Instrument returns a client option recording the client's requests under
+// the client label name. Clients sharing a name are reported together; their
+// rate-limit gauge shows the lowest remaining budget among them.
+func (m *Metrics) Instrument(name string) carthooks.Option {
+	return func(c *carthooks.Client) {
+		m.mu.Lock()
+		m.clients[name] = append(m.clients[name], c)
+		m.mu.Unlock()
+		carthooks.WithObserver(func(info carthooks.RequestInfo) {
+			m.observe(name, c, info)
+		})(c)
+	}
+}
+
+// Unregister stops recording the requests of c and drops it from the
+// rate-limit gauge, so that Metrics no longer holds on to it. The counts
+// already recorded under its name are kept.
+func (m *Metrics) Unregister(c *carthooks.Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, clients := range m.clients {
+		kept := clients[:0]
+		for _, other := range clients {
+			if other != c {
+				kept = append(kept, other)
+			}
+		}
+		for i := len(kept); i < len(clients); i++ {
+			clients[i] = nil
+		}
+		if len(kept) == 0 {
+			delete(m.clients, name)
+		} else {
+			m.clients[name] = kept
+		}
+	}
+}
+
+func (m *Metrics) observe(name string, c *carthooks.Client, info carthooks.RequestInfo) {
+	l := labels{client: name, method: info.Method, route: info.Route}
+	status := l
+	status.status = strconv.Itoa(info.StatusCode)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.registered(name, c) {
+		return
+	}
+	m.requests[status]++
+	if info.Err != nil {
+		e := status
+		var apiErr *carthooks.APIError
+		if errors.As(info.Err, &apiErr) {
+			e.key = apiErr.Key
+		}
+		m.errors[e]++
+	}
+	if info.Attempt > 1 {
+		m.retries[l]++
+	}
+	h := m.durations[l]
+	if h == nil {
+		h = &histogram{counts: make([]float64, len(m.buckets))}
+		m.durations[l] = h
+	}
+	seconds := info.Duration.Seconds()
+	for i, upper := range m.buckets {
+		if seconds <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *Metrics) registered(name string, c *carthooks.Client) bool {
+	for _, other := range m.clients[name] {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Type is the kind of a metric family.
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Family is a snapshot of one metric with all its series, as returned by
+// Collect.
+type Family struct {
+	Name string
+	Help string
+	Type Type
+	// LabelNames are the names of the labels of every sample, in the order
+	// of Sample.LabelValues. An empty value means the label is not set.
+	LabelNames []string
+	Samples    []Sample
+}
+
+// Sample is one series of a Family.
+type Sample struct {
+	LabelValues []string
+	// Value is the value of a counter or gauge.
+	Value float64
+	// Count, Sum and Buckets are the observations of a histogram, Buckets
+	// holding the cumulative count for each upper bound.
+	Count   uint64
+	Sum     float64
+	Buckets map[float64]uint64
+}
+
+// Collect returns a snapshot of the metrics, in the order WriteTo writes
+// them, with the samples of each family sorted by their labels.
+func (m *Metrics) Collect() []Family {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	families := []Family{
+		counterFamily("carthooks_requests_total", "API requests by response status.",
+			[]string{"client", "method", "route", "status"}, m.requests),
+		counterFamily("carthooks_request_errors_total", "Failed API requests by status and error key.",
+			[]string{"client", "method", "route", "status", "key"}, m.errors),
+		counterFamily("carthooks_retries_total", "Retried attempts of API requests.",
+			[]string{"client", "method", "route"}, m.retries),
+	}
+
+	durations := Family{Name: "carthooks_request_duration_seconds", Help: "Duration of API requests.",
+		Type: Histogram, LabelNames: []string{"client", "method", "route"}}
+	for _, l := range sortedLabels(m.durations) {
+		h := m.durations[l]
+		buckets := make(map[float64]uint64, len(m.buckets))
+		for i, upper := range m.buckets {
+			buckets[upper] = uint64(h.counts[i])
+		}
+		durations.Samples = append(durations.Samples, Sample{LabelValues: l.values(durations.LabelNames),
+			Count: uint64(h.count), Sum: h.sum, Buckets: buckets})
+	}
+	families = append(families, durations)
+
+	remaining := Family{Name: "carthooks_rate_limit_remaining", Help: "Requests left in the API rate-limit window.",
+		Type: Gauge, LabelNames: []string{"client"}}
+	names := make([]string, 0, len(m.clients))
+	for name := range m.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lowest, known := 0, false
+		for _, c := range m.clients[name] {
+			st := c.RateLimitStatus()
+			if st.Known && (!known || st.Remaining < lowest) {
+				lowest, known = st.Remaining, true
+			}
+		}
+		if known {
+			remaining.Samples = append(remaining.Samples, Sample{LabelValues: []string{name}, Value: float64(lowest)})
+		}
+	}
+	return append(families, remaining)
+}
+
+func counterFamily(name, help string, labelNames []string, values map[labels]float64) Family {
+	f := Family{Name: name, Help: help, Type: Counter, LabelNames: labelNames}
+	for _, l := range sortedLabels(values) {
+		f.Samples = append(f.Samples, Sample{LabelValues: l.values(labelNames), Value: values[l]})
+	}
+	return f
+}
+
+// ServeHTTP writes the metrics for a Prometheus scrape.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, f := range m.Collect() {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.Name, f.Help, f.Name, f.Type)
+		for _, s := range f.Samples {
+			if f.Type != Histogram {
+				fmt.Fprintf(&b, "%s%s %s\n", f.Name, formatLabels(f.LabelNames, s.LabelValues, ""), formatFloat(s.Value))
+				continue
+			}
+			bounds := make([]float64, 0, len(s.Buckets))
+			for upper := range s.Buckets {
+				bounds = append(bounds, upper)
+			}
+			sort.Float64s(bounds)
+			for _, upper := range bounds {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.Name,
+					formatLabels(f.LabelNames, s.LabelValues, `le="`+formatFloat(upper)+`"`), s.Buckets[upper])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", f.Name, formatLabels(f.LabelNames, s.LabelValues, `le="+Inf"`), s.Count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", f.Name, formatLabels(f.LabelNames, s.LabelValues, ""), formatFloat(s.Sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", f.Name, formatLabels(f.LabelNames, s.LabelValues, ""), s.Count)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func sortedLabels[V any](m map[labels]V) []labels {
+	keys := make([]labels, 0, len(m))
+	for l := range m {
+		keys = append(keys, l)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].format() < keys[j].format()
+	})
+	return keys
+}
+
+// values returns the values of the named labels, in order.
+func (l labels) values(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		switch name {
+		case "client":
+			out[i] = l.client
+		case "method":
+			out[i] = l.method
+		case "route":
+			out[i] = l.route
+		case "status":
+			out[i] = l.status
+		case "key":
+			out[i] = l.key
+		}
+	}
+	return out
+}
+
+// format renders the labels as a Prometheus label set, by which Collect
+// orders samples.
+func (l labels) format() string {
+	return formatLabels([]string{"client", "method", "route", "status", "key"},
+		[]string{l.client, l.method, l.route, l.status, l.key}, "")
+}
+
+// formatLabels renders the labels with non-empty values, and extra if given,
+// as a Prometheus label set. The first label, the client, is always written.
+func formatLabels(names, values []string, extra string) string {
+	var pairs []string
+	for i, name := range names {
+		if i == 0 || values[i] != "" {
+			pairs = append(pairs, name+`="`+escape(values[i])+`"`)
+		}
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
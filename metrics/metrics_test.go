@@ -0,0 +1,65 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+	"github.com/carthooks/carthooks-sdk-golang/metrics"
+)
+
+func TestUnregister(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	m := metrics.New()
+	c := s.Client(m.Instrument("default"))
+	ctx := context.Background()
+
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, item.ID); err != nil {
+		t.Fatal(err)
+	}
+	m.Unregister(c)
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, item.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	m.WriteTo(&out)
+	want := `carthooks_requests_total{client="default",method="GET",route="/v1/apps/:id/collections/:id/items/:id",status="200"} 1`
+	if !strings.Contains(out.String(), want+"\n") {
+		t.Errorf("metrics do not contain %q:\n%s", want, out.String())
+	}
+}
+
+func TestCollect(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	m := metrics.New()
+	c := s.Client(m.Instrument("default"))
+	if _, err := c.GetItemByIDContext(context.Background(), 1, 2, item.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	families := map[string]metrics.Family{}
+	for _, f := range m.Collect() {
+		families[f.Name] = f
+	}
+	requests := families["carthooks_requests_total"]
+	if requests.Type != metrics.Counter || len(requests.Samples) != 1 {
+		t.Fatalf("requests = %+v", requests)
+	}
+	want := []string{"default", "GET", "/v1/apps/:id/collections/:id/items/:id", "200"}
+	if got := requests.Samples[0].LabelValues; strings.Join(got, " ") != strings.Join(want, " ") || requests.Samples[0].Value != 1 {
+		t.Errorf("requests sample = %+v, want labels %q and value 1", requests.Samples[0], want)
+	}
+	durations := families["carthooks_request_duration_seconds"]
+	if durations.Type != metrics.Histogram || len(durations.Samples) != 1 {
+		t.Fatalf("durations = %+v", durations)
+	}
+	if d := durations.Samples[0]; d.Count != 1 || len(d.Buckets) == 0 {
+		t.Errorf("durations sample = %+v, want one observation", d)
+	}
+}
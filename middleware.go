@@ -0,0 +1,121 @@
+package carthooks
+
+import (
+	"net/http"
+	"time"
+)
+
+// RoundTripFunc sends an HTTP request and returns its response, like
+// http.RoundTripper.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of API requests, e.g. to sign them, record
+// metrics or inject faults. It runs after the client's own rate limiting,
+// interceptors and circuit breaker, right around the HTTP exchange, and sees
+// every attempt of a retried request.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middlewares to the client. The first one added is the
+// outermost: it sees the request first and the response last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// roundTrip sends req through the middlewares and the HTTP client.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	if c.chain == nil {
+		return c.httpClient.Do(req)
+	}
+	return c.chain(req)
+}
+
+// buildChain composes the middlewares around the HTTP client.
+func (c *Client) buildChain() {
+	if len(c.middleware) == 0 {
+		return
+	}
+	next := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return c.httpClient.Do(req)
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	c.chain = next
+}
+
+// LoggingMiddleware logs every HTTP exchange to logger at debug level, or at
+// warn level if it failed.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			switch {
+			case err != nil:
+				logger.Warn("carthooks: http request failed", "method", req.Method, "route", routeOf(req.URL.String()),
+					"duration", time.Since(start), "error", err)
+			case resp.StatusCode >= 400:
+				logger.Warn("carthooks: http request", "method", req.Method, "route", routeOf(req.URL.String()),
+					"status", resp.StatusCode, "duration", time.Since(start))
+			default:
+				logger.Debug("carthooks: http request", "method", req.Method, "route", routeOf(req.URL.String()),
+					"status", resp.StatusCode, "duration", time.Since(start))
+			}
+			return resp, err
+		}
+	}
+}
+
+// RetryMiddleware retries requests that fail with 429, 500, 502, 503 or 504,
+// or with a network error, up to maxAttempts attempts in total, waiting as
+// the response's Retry-After asks or else as backoff says
+// (ExponentialBackoff from DefaultRetryBaseDelay if nil). Like WithRetry, it
+// only retries GET, PUT and DELETE requests and requests carrying an
+// Idempotency-Key, and only if their body can be sent again.
+//
+// WithRetry retries above the middlewares instead; use one or the other.
+func RetryMiddleware(maxAttempts int, backoff Backoff) Middleware {
+	p := RetryPolicy{MaxAttempts: maxAttempts, Backoff: backoff}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			retryable := (idempotent(req.Method) || req.Header.Get(idempotencyKeyHeader) != "") &&
+				(req.Body == nil || req.GetBody != nil)
+			for attempt := 1; ; attempt++ {
+				resp, err := next(req)
+				if !retryable || req.Context().Err() != nil {
+					return resp, err
+				}
+				var status int
+				var retryAfter time.Duration
+				if err == nil {
+					status = resp.StatusCode
+					retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+				}
+				delay, ok := p.delay(attempt, status, retryAfter)
+				if !ok {
+					return resp, err
+				}
+				if resp != nil {
+					drainAndClose(resp.Body)
+				}
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				}
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req = req.Clone(req.Context())
+					req.Body = body
+				}
+			}
+		}
+	}
+}
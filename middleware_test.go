@@ -0,0 +1,81 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestMiddlewareSeesEveryRequest(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "file content")
+	}))
+	defer files.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			io.WriteString(w, `{"access_token":"access","expires_in":3600}`)
+		default:
+			io.WriteString(w, `{"data":{"id":3,"fields":{}}}`)
+		}
+	}))
+	defer api.Close()
+
+	var mu sync.Mutex
+	var seen []string
+	record := func(next carthooks.RoundTripFunc) carthooks.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			seen = append(seen, req.Method+" "+req.URL.Path)
+			mu.Unlock()
+			return next(req)
+		}
+	}
+	ctx := context.Background()
+	c := carthooks.NewClient("", carthooks.WithBaseURL(api.URL),
+		carthooks.WithClientCredentials("client", "secret"),
+		carthooks.WithMiddleware(record))
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	body, err := c.OpenFile(ctx, carthooks.Attachment{URL: files.URL + "/f.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+
+	want := []string{"POST /oauth/token", "GET /v1/apps/1/collections/2/items/3", "GET /f.pdf"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("middleware saw %q, want %q", seen, want)
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"error":{"key":"ERROR_UNAVAILABLE"}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"id":3,"fields":{}}}`)
+	}))
+	defer s.Close()
+
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL),
+		carthooks.WithMiddleware(carthooks.RetryMiddleware(3, carthooks.ConstantBackoff(0))))
+	if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
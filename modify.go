@@ -0,0 +1,63 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxModifyAttempts bounds the read-modify-write cycles of ModifyItem, AddTag
+// and RemoveTag when the item keeps changing underneath.
+const maxModifyAttempts = 5
+
+// ErrNoVersion is returned by ModifyItem, AddTag and RemoveTag when the
+// server sends no version (ETag) with an item, so that writing it back could
+// silently overwrite a concurrent change.
+var ErrNoVersion = errors.New("carthooks: item has no version to write it conditionally")
+
+// ModifyItem updates an item based on its current state. It reads the item,
+// passes it to fn and writes back the fields fn returns with PatchItem,
+// leaving the others as they are, conditionally on the item's version (see
+// IfMatch). If the item changed in between, the cycle is repeated with a
+// fresh read, up to five times, after which the *ConflictError is returned.
+// If fn returns no fields, nothing is written; if the server reports no
+// version for the item, nothing is written either and ErrNoVersion is
+// returned.
+//
+// fn may be called several times and should not have side effects.
+func (c *Client) ModifyItem(ctx context.Context, appID, collectionID, itemID int, fn func(item *Item) (map[string]interface{}, error)) error {
+	ctx = withOperation(ctx, "ModifyItem")
+	return c.modifyItem(ctx, ItemRef{AppID: appID, CollectionID: collectionID, ItemID: itemID}, fn)
+}
+
+func (c *Client) modifyItem(ctx context.Context, ref ItemRef, fn func(item *Item) (map[string]interface{}, error), opts ...ItemOption) error {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := c.waitRetryAfter(ctx); err != nil {
+				return err
+			}
+		}
+		err := c.modifyItemOnce(ctx, ref, fn, opts...)
+		if !errors.Is(err, ErrConflict) || attempt == maxModifyAttempts {
+			return err
+		}
+	}
+}
+
+func (c *Client) modifyItemOnce(ctx context.Context, ref ItemRef, fn func(item *Item) (map[string]interface{}, error), opts ...ItemOption) error {
+	// A cached copy would only ever conflict.
+	readCtx := ContextWithRequestOptions(ctx, WithNoCache())
+	item, err := c.GetItemByIDContext(readCtx, ref.AppID, ref.CollectionID, ref.ItemID, opts...)
+	if err != nil {
+		return err
+	}
+	data, err := fn(item)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	if item.ETag == "" {
+		return fmt.Errorf("%w: item %d", ErrNoVersion, ref.ItemID)
+	}
+	_, err = c.PatchItem(ctx, ref.AppID, ref.CollectionID, ref.ItemID, data, IfMatch(item.ETag))
+	return err
+}
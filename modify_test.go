@@ -0,0 +1,94 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestModifyItemKeepsOtherFields(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"count": 1, "title": "keep me"})
+	c := s.Client()
+
+	err := c.ModifyItem(context.Background(), 1, 2, item.ID, func(item *carthooks.Item) (map[string]interface{}, error) {
+		return map[string]interface{}{"count": item.Fields["count"].(float64) + 1}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Item(1, 2, item.ID)
+	if want := map[string]interface{}{"count": float64(2), "title": "keep me"}; !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("got %v, want %v", got.Fields, want)
+	}
+}
+
+func TestAddTagKeepsOtherFields(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"tags": []interface{}{"a"}, "title": "keep me"})
+
+	if err := s.Client().AddTag(context.Background(), 1, 2, []int{item.ID}, "tags", "b"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Item(1, 2, item.ID)
+	if want := map[string]interface{}{"tags": []interface{}{"a", "b"}, "title": "keep me"}; !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("got %v, want %v", got.Fields, want)
+	}
+}
+
+func TestModifyItemRetriesOnConflict(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"count": 1})
+	c := s.Client()
+	ctx := context.Background()
+
+	calls := 0
+	err := c.ModifyItem(ctx, 1, 2, item.ID, func(item *carthooks.Item) (map[string]interface{}, error) {
+		calls++
+		if calls == 1 {
+			// Another writer gets in between the read and the write.
+			if _, err := s.Client().PatchItem(ctx, 1, 2, item.ID, map[string]interface{}{"count": 10}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return map[string]interface{}{"count": item.Fields["count"].(float64) + 1}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Item(1, 2, item.ID)
+	if got.Fields["count"] != float64(11) || calls != 2 {
+		t.Errorf("count = %v after %d calls, want 11 after 2", got.Fields["count"], calls)
+	}
+}
+
+func TestModifyItemWithoutVersion(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		io.WriteString(w, `{"data":{"id":3,"fields":{"count":1}}}`)
+	}))
+	defer srv.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL))
+
+	err := c.ModifyItem(context.Background(), 1, 2, 3, func(item *carthooks.Item) (map[string]interface{}, error) {
+		return map[string]interface{}{"count": 2}, nil
+	})
+	if !errors.Is(err, carthooks.ErrNoVersion) {
+		t.Errorf("err = %v, want ErrNoVersion", err)
+	}
+	if fmt.Sprint(methods) != "[GET]" {
+		t.Errorf("requests = %v, want only the read", methods)
+	}
+}
@@ -0,0 +1,248 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldNaming maps a Go struct field name to an API field name. It applies to
+// struct fields without a json tag name; tagged fields keep their tag.
+type FieldNaming func(goName string) string
+
+// SnakeCase maps CreatedAt to created_at and UserID to user_id.
+func SnakeCase(goName string) string {
+	return strings.Join(splitWords(goName), "_")
+}
+
+// CamelCase maps CreatedAt to createdAt and UserID to userId.
+func CamelCase(goName string) string {
+	words := splitWords(goName)
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits a Go identifier into lower-case words, keeping acronyms
+// together: HTTPServerID becomes http, server, id.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		boundary := i == len(runes) || runes[i] == '_'
+		if !boundary && unicode.IsUpper(runes[i]) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			boundary = prevLower || (unicode.IsUpper(runes[i-1]) && nextLower)
+		}
+		if boundary {
+			if word := string(runes[start:i]); word != "" && word != "_" {
+				words = append(words, strings.ToLower(strings.Trim(word, "_")))
+			}
+			start = i
+			if i < len(runes) && runes[i] == '_' {
+				start++
+			}
+		}
+	}
+	return words
+}
+
+// WithFieldNaming sets how untagged struct fields are named in the struct
+// based helpers (CreateItemFromStruct, UpdateItemFromStruct, DecodeItem).
+// Map-based calls are not affected. By default the Go field name is used.
+func WithFieldNaming(naming FieldNaming) Option {
+	return func(c *Client) {
+		c.naming = naming
+	}
+}
+
+// structField is an exported struct field together with its API name.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the fields of struct type t as encoding/json would,
+// flattening embedded structs and naming untagged fields with naming.
+func structFields(t reflect.Type, naming FieldNaming) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.Tag.Get("carthooks") == "extra" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, sub := range structFields(ft, naming) {
+				sub.index = append([]int{i}, sub.index...)
+				fields = append(fields, sub)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+			if naming != nil {
+				name = naming(f.Name)
+			}
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("carthooks: expected a struct, got %T", v)
+	}
+	return rv, nil
+}
+
+// encodeStruct converts a struct into an item fields map.
+func encodeStruct(v interface{}, naming FieldNaming) (map[string]interface{}, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{}
+	for _, f := range structFields(rv.Type(), naming) {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (f.omitEmpty && fv.IsZero()) {
+			continue
+		}
+		data[f.name] = fv.Interface()
+	}
+	return data, nil
+}
+
+// decodeStruct fills the struct pointed to by v from an item fields map.
+func decodeStruct(fields map[string]interface{}, v interface{}, naming FieldNaming) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("carthooks: decode target must be a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("carthooks: decode target must point to a struct, got %T", v)
+	}
+	mapped := map[string]bool{}
+	for _, f := range structFields(rv.Type(), naming) {
+		mapped[f.name] = true
+		value, ok := fields[f.name]
+		if !ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fv := allocFieldByIndex(rv, f.index)
+		if err := json.Unmarshal(raw, fv.Addr().Interface()); err != nil {
+			return fmt.Errorf("carthooks: decode field %q: %w", f.name, err)
+		}
+	}
+	return decodeExtra(rv, fields, mapped)
+}
+
+// decodeExtra stores the fields not mapped to any struct field in the map
+// field tagged `carthooks:"extra"`, if the struct has one.
+func decodeExtra(rv reflect.Value, fields map[string]interface{}, mapped map[string]bool) error {
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if f.Tag.Get("carthooks") != "extra" {
+			continue
+		}
+		if f.Type != reflect.TypeOf(map[string]interface{}(nil)) {
+			return fmt.Errorf("carthooks: extra field %s must be a map[string]interface{}", f.Name)
+		}
+		extra := map[string]interface{}{}
+		for name, value := range fields {
+			if !mapped[name] {
+				extra[name] = value
+			}
+		}
+		rv.Field(i).Set(reflect.ValueOf(extra))
+		return nil
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that reports false instead of
+// panicking on nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// allocFieldByIndex is reflect.Value.FieldByIndex that allocates nil embedded
+// pointers on the way.
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// CreateItemFromStruct creates an item from the exported fields of v, named
+// by their json tags or the client's field naming.
+func (c *Client) CreateItemFromStruct(ctx context.Context, appID, collectionID int, v interface{}) (*Item, error) {
+	data, err := encodeStruct(v, c.naming)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateItemContext(ctx, appID, collectionID, data)
+}
+
+// UpdateItemFromStruct updates an item from the exported fields of v, named as
+// in CreateItemFromStruct.
+func (c *Client) UpdateItemFromStruct(ctx context.Context, appID, collectionID, itemID int, v interface{}) (*Response, error) {
+	data, err := encodeStruct(v, c.naming)
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateItemContext(ctx, appID, collectionID, itemID, data)
+}
+
+// DecodeItem fills the struct pointed to by v from the item's fields, matching
+// them by json tag or the client's field naming. Fields missing from the item
+// are left untouched.
+func (c *Client) DecodeItem(item *Item, v interface{}) error {
+	return decodeStruct(item.fieldsWithID(), v, c.naming)
+}
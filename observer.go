@@ -0,0 +1,113 @@
+package carthooks
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes one HTTP exchange with the API, as passed to the
+// observers registered with WithObserver.
+type RequestInfo struct {
+	Method     string
+	URL        string
+	Route      string
+	StatusCode int
+	Duration   time.Duration
+	TraceId    string
+	Err        error
+
+	// Operation names the composite SDK call, such as GetAll, that issued
+	// the request. It is empty for single requests.
+	Operation string
+
+	// Attempt numbers the tries of a request retried under WithRetry,
+	// starting at 1.
+	Attempt int
+
+	// RequestBytes is the size of the request body as sent, after any
+	// compression set with WithRequestCompression. ResponseBytes counts
+	// the response body bytes read by the SDK; for streamed bodies it is
+	// counted as the caller reads and reported when the body is closed.
+	//
+	// Both are body sizes only, without headers or chunked framing. When
+	// the transport decompresses a gzip response transparently,
+	// ResponseBytes is the decompressed size, which is larger than what was
+	// transferred.
+	RequestBytes  int64
+	ResponseBytes int64
+
+	// ThrottleWait is how long the request waited for the client-side rate
+	// limiter set with WithRateLimiter. Duration does not include it.
+	ThrottleWait time.Duration
+
+	// ConnReused reports whether the request went out on a pooled
+	// connection rather than a newly opened one.
+	ConnReused bool
+}
+
+// WithObserver registers fn to be called after every HTTP exchange with the
+// API, e.g. to record metrics. Observers registered by several options are
+// called in order. fn must be safe for concurrent use.
+func WithObserver(fn func(RequestInfo)) Option {
+	return func(c *Client) {
+		c.observers = append(c.observers, fn)
+	}
+}
+
+// WithRequestHook registers fn to be called just before each HTTP request is
+// sent, after any rate-limit wait, with the fields known at that point:
+// method, URL, route, operation, attempt, request size and throttle wait.
+// Paired with WithObserver, which sees the outcome, it brackets every
+// exchange. fn must be safe for concurrent use.
+func WithRequestHook(fn func(RequestInfo)) Option {
+	return func(c *Client) {
+		c.requestHook = fn
+	}
+}
+
+// WithRequestLogger registers fn to be called after every HTTP exchange with
+// the method, URL, status code (zero if no response arrived) and duration. It
+// is a lighter alternative to WithObserver and may be combined with it. The
+// client writes nothing anywhere unless a logger or observer is set.
+func WithRequestLogger(fn func(method, url string, status int, dur time.Duration)) Option {
+	return func(c *Client) {
+		c.requestLogger = fn
+	}
+}
+
+func (c *Client) beforeSend(info RequestInfo) {
+	if c.requestHook != nil {
+		c.requestHook(info)
+	}
+}
+
+func (c *Client) observe(info RequestInfo) {
+	for _, fn := range c.observers {
+		fn(info)
+	}
+	if c.requestLogger != nil {
+		c.requestLogger(info.Method, info.URL, info.StatusCode, info.Duration)
+	}
+}
+
+// countingBody counts the bytes read from a streamed response body and
+// reports the exchange to the observer once, when the body is closed.
+type countingBody struct {
+	io.ReadCloser
+	n      int64
+	once   sync.Once
+	report func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := drainAndClose(b.ReadCloser)
+	b.once.Do(func() { b.report(b.n) })
+	return err
+}
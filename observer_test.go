@@ -0,0 +1,82 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+// captureOutput returns what fn writes to stdout and stderr.
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestClientIsSilentByDefault(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	s.AddItem(1, 2, map[string]interface{}{"email": "someone@example.com"})
+	c := s.Client()
+
+	out := captureOutput(t, func() {
+		if _, err := c.Query(1, 2).Where("email", carthooks.OpEq, "someone@example.com").Get(); err != nil {
+			t.Error(err)
+		}
+		if _, err := c.GetItemByID(1, 2, 999); err == nil {
+			t.Error("no error for a missing item")
+		}
+	})
+	if out != "" {
+		t.Errorf("client wrote %q", out)
+	}
+}
+
+func TestWithRequestLogger(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+
+	type call struct {
+		method, url string
+		status      int
+	}
+	var calls []call
+	c := s.Client(carthooks.WithRequestLogger(func(method, url string, status int, dur time.Duration) {
+		calls = append(calls, call{method, url, status})
+	}))
+	out := captureOutput(t, func() {
+		if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 1); err != nil {
+			t.Error(err)
+		}
+	})
+	if out != "" {
+		t.Errorf("client wrote %q", out)
+	}
+	if len(calls) != 1 || calls[0].method != http.MethodGet || calls[0].status != http.StatusOK ||
+		!strings.HasSuffix(calls[0].url, "/v1/apps/1/collections/2/items/1") {
+		t.Errorf("logger got %+v", calls)
+	}
+}
@@ -0,0 +1,65 @@
+package carthooks
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithClock replaces the clock used for time-dependent logic. It is mainly
+// useful in tests.
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithBaseURL sets the API endpoint, e.g. a staging or mock server. It takes
+// precedence over the CARTHOOKS_API_URL environment variable, which in turn
+// overrides the default https://api.carthooks.com. A trailing slash is
+// dropped.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseUrl = strings.TrimRight(u, "/")
+	}
+}
+
+// WithHTTPClient makes the client send requests through hc, e.g. to share a
+// transport tuned for connection pooling. It takes precedence over
+// WithTimeout, whose value is then ignored; set hc.Timeout instead.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout sets the overall time limit of each request, including reading
+// the response body, on the default HTTP client. Without it requests are
+// only bounded by their context.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with API requests, e.g. to
+// identify an integration in server logs. Headers set explicitly on a
+// request take precedence.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHydrateCreated makes CreateItem fetch the new item with GetItemByID
+// when the server echoes only its ID, so the returned item carries all
+// fields, including those computed by the server. Off by default, which
+// saves the extra request.
+func WithHydrateCreated() Option {
+	return func(c *Client) {
+		c.hydrateCreated = true
+	}
+}
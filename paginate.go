@@ -0,0 +1,209 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DefaultMaxPages is the number of pages GetAll fetches before giving up with
+// ErrPaginationLimit, unless changed with Query.MaxPages.
+const DefaultMaxPages = 10000
+
+// ErrPaginationLimit is returned by GetAll when it reaches the page limit
+// before the server reports the last page.
+var ErrPaginationLimit = errors.New("carthooks: pagination limit reached")
+
+// MaxPages sets how many pages GetAll may fetch. A value of zero or less
+// disables the limit.
+func (q *Query) MaxPages(n int) *Query {
+	q.maxPages = n
+	q.maxPagesSet = true
+	return q
+}
+
+// GetAll fetches every page of the query, starting from the page set on the
+// query (or the first one), and returns all items.
+//
+// It stops at the last page reported by the pagination meta or, when the
+// server sends no page count, at the first page shorter than the page size
+// the server reports (which may be less than requested). If the page limit is
+// hit first, the items gathered so far are returned together with an error
+// wrapping ErrPaginationLimit.
+func (q *Query) GetAll(ctx context.Context) ([]Item, error) {
+	ctx = withOperation(ctx, "GetAll")
+	all := []Item{}
+	err := q.eachPage(ctx, func(items []Item) error {
+		all = append(all, items...)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrPaginationLimit) {
+		return nil, err
+	}
+	return all, err
+}
+
+// All is GetAll.
+func (q *Query) All(ctx context.Context) ([]Item, error) {
+	return q.GetAll(ctx)
+}
+
+// Each calls fn for every item of the query, fetching pages as GetAll does
+// but without holding more than one page in memory. It stops at the first
+// error returned by fn and returns it.
+func (q *Query) Each(ctx context.Context, fn func(Item) error) error {
+	ctx = withOperation(ctx, "Each")
+	return q.eachPage(ctx, func(items []Item) error {
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachPage calls fn with each page of the query in turn, fetching pages as
+// GetAll does. It stops at the first error returned by fn and returns it.
+func (q *Query) ForEachPage(ctx context.Context, fn func([]Item) error) error {
+	return q.eachPage(withOperation(ctx, "ForEachPage"), fn)
+}
+
+// eachPage calls fn with each page of the query until the last page, an
+// error, or the page limit.
+func (q *Query) eachPage(ctx context.Context, fn func([]Item) error) error {
+	pg := q.pager()
+	for !pg.done {
+		items, err := pg.next(ctx)
+		if err != nil {
+			return err
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pager fetches the pages of a query one at a time.
+type pager struct {
+	q        *Query
+	page     int
+	cursor   string
+	maxPages int
+	fetched  int
+	total    int
+	done     bool
+}
+
+func (q *Query) pager() *pager {
+	pg := &pager{q: q, page: q.page, cursor: q.cursor, maxPages: DefaultMaxPages}
+	if pg.page < 1 {
+		pg.page = 1
+	}
+	if q.maxPagesSet {
+		pg.maxPages = q.maxPages
+	}
+	return pg
+}
+
+// next fetches the next page and sets done if it was the last one.
+func (pg *pager) next(ctx context.Context) ([]Item, error) {
+	if pg.maxPages > 0 && pg.fetched >= pg.maxPages {
+		return nil, fmt.Errorf("%w: stopped after %d pages and %d items", ErrPaginationLimit, pg.fetched, pg.total)
+	}
+	if err := pg.q.client.waitRetryAfter(ctx); err != nil {
+		return nil, err
+	}
+	if pg.q.cursorMode {
+		items, next, err := pg.q.fetchCursor(ctx, pg.cursor)
+		if err != nil {
+			return nil, err
+		}
+		pg.fetched++
+		pg.total += len(items)
+		pg.cursor, pg.done = next, next == ""
+		return items, nil
+	}
+	params := pg.q.params()
+	params.Set("pagination[page]", strconv.Itoa(pg.page))
+	rsp, items, err := pg.q.fetch(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	pg.fetched++
+	pg.total += len(items)
+	p, ok := pg.q.client.pagination(rsp.Meta)
+	pg.done = lastPage(p, ok, pg.page, len(items), pg.q.limit)
+	pg.page++
+	return items, nil
+}
+
+// Iterator walks the items of a query, fetching the next page when the
+// current one is used up. Create one with Query.Iterate:
+//
+//	it := q.Iterate(ctx)
+//	for it.Next() {
+//		item := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	ctx   context.Context
+	pager *pager
+	items []Item
+	item  Item
+	err   error
+}
+
+// Iterate returns an Iterator over all items of the query, paging as GetAll
+// does. Nothing is fetched until the first call to Next.
+func (q *Query) Iterate(ctx context.Context) *Iterator {
+	return &Iterator{ctx: withOperation(ctx, "Iterate"), pager: q.pager()}
+}
+
+// Next advances to the next item, fetching a page if needed. It returns false
+// when there are no more items or a request failed; see Err.
+func (it *Iterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.err != nil || it.pager.done {
+			return false
+		}
+		it.items, it.err = it.pager.next(it.ctx)
+	}
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the item Next advanced to.
+func (it *Iterator) Item() Item {
+	return it.item
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// lastPage reports whether page, which returned n items and the pagination
+// p (if ok), is the last one. An empty page always ends the listing, so a
+// page count that is too high cannot keep it going. Otherwise it trusts the
+// page count from the meta; without one, a short page ends the listing. A
+// short page is judged against the page size the server reports, since it may
+// clamp the requested size to its own maximum.
+func lastPage(p Pagination, ok bool, page, n, requestedSize int) bool {
+	if n == 0 {
+		return true
+	}
+	if ok && p.PageCount > 0 {
+		return page >= p.PageCount
+	}
+	size := requestedSize
+	if ok && p.PageSize > 0 {
+		size = p.PageSize
+	}
+	return size > 0 && n < size
+}
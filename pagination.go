@@ -0,0 +1,95 @@
+package carthooks
+
+import "errors"
+
+// ErrNoPagination is returned when a response carries no pagination meta.
+var ErrNoPagination = errors.New("carthooks: response has no pagination meta")
+
+// Pagination is the pagination block of a list response.
+type Pagination struct {
+	Page      int
+	PageSize  int
+	PageCount int
+	Total     int
+	// HasTotal is false when the server sent no total, e.g. for queries
+	// made WithoutCount.
+	HasTotal bool
+	// NextCursor is the cursor of the next page of a query using cursor
+	// pagination, empty on the last page. HasCursor reports whether the
+	// server paginated by cursor at all.
+	NextCursor string
+	HasCursor  bool
+}
+
+// Pagination returns the pagination block of a list response, read as set
+// with WithPaginationExtractor. It fails with ErrNoPagination if the meta
+// has none.
+func (r *Response) Pagination() (Pagination, error) {
+	extract := defaultPagination
+	if r.client != nil && r.client.paginationFn != nil {
+		extract = r.client.paginationFn
+	}
+	p, ok := extract(r.Meta)
+	if !ok {
+		return Pagination{}, ErrNoPagination
+	}
+	return p, nil
+}
+
+// PaginationExtractor reads the pagination block from a response's meta. ok
+// is false when the meta carries none.
+type PaginationExtractor func(meta map[string]interface{}) (p Pagination, ok bool)
+
+// WithPaginationExtractor sets how pagination is read from response meta,
+// for API versions or proxies that nest it differently. The default is
+// PaginationAt("pagination"), matching meta.pagination.total.
+func WithPaginationExtractor(fn PaginationExtractor) Option {
+	return func(c *Client) {
+		c.paginationFn = fn
+	}
+}
+
+// PaginationAt returns an extractor reading page, pageSize, pageCount, total
+// and nextCursor from the object at path within the meta. With no path they
+// are read from the meta itself, as in meta.total.
+func PaginationAt(path ...string) PaginationExtractor {
+	return func(meta map[string]interface{}) (p Pagination, ok bool) {
+		block := meta
+		for _, key := range path {
+			if block, ok = block[key].(map[string]interface{}); !ok {
+				return p, false
+			}
+		}
+		var hasPage, hasSize, hasCount bool
+		p.Page, hasPage = metaInt(block["page"])
+		p.PageSize, hasSize = metaInt(block["pageSize"])
+		p.PageCount, hasCount = metaInt(block["pageCount"])
+		p.Total, p.HasTotal = metaInt(block["total"])
+		var cursor interface{}
+		if cursor, p.HasCursor = block["nextCursor"]; p.HasCursor {
+			p.NextCursor, _ = cursor.(string)
+		}
+		return p, hasPage || hasSize || hasCount || p.HasTotal || p.HasCursor || len(path) > 0
+	}
+}
+
+var defaultPagination = PaginationAt("pagination")
+
+// pagination reads the pagination block of meta with the configured
+// extractor.
+func (c *Client) pagination(meta map[string]interface{}) (Pagination, bool) {
+	if c.paginationFn != nil {
+		return c.paginationFn(meta)
+	}
+	return defaultPagination(meta)
+}
+
+func metaInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package carthooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestPaginationExtractors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		extract carthooks.PaginationExtractor
+		meta    string
+		want    carthooks.Pagination
+		wantOK  bool
+	}{
+		{
+			name:    "default nested",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `{"pagination":{"page":2,"pageSize":20,"pageCount":5,"total":97}}`,
+			want:    carthooks.Pagination{Page: 2, PageSize: 20, PageCount: 5, Total: 97, HasTotal: true},
+			wantOK:  true,
+		},
+		{
+			name:    "flat",
+			extract: carthooks.PaginationAt(),
+			meta:    `{"page":1,"pageSize":50,"pageCount":2,"total":60}`,
+			want:    carthooks.Pagination{Page: 1, PageSize: 50, PageCount: 2, Total: 60, HasTotal: true},
+			wantOK:  true,
+		},
+		{
+			name:    "deeply nested",
+			extract: carthooks.PaginationAt("list", "paging"),
+			meta:    `{"list":{"paging":{"page":3,"pageSize":10}}}`,
+			want:    carthooks.Pagination{Page: 3, PageSize: 10},
+			wantOK:  true,
+		},
+		{
+			name:    "without count",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `{"pagination":{"page":1,"pageSize":20}}`,
+			want:    carthooks.Pagination{Page: 1, PageSize: 20},
+			wantOK:  true,
+		},
+		{
+			name:    "cursor",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `{"pagination":{"pageSize":20,"nextCursor":"abc"}}`,
+			want:    carthooks.Pagination{PageSize: 20, NextCursor: "abc", HasCursor: true},
+			wantOK:  true,
+		},
+		{
+			name:    "last cursor page",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `{"pagination":{"pageSize":20,"nextCursor":null}}`,
+			want:    carthooks.Pagination{PageSize: 20, HasCursor: true},
+			wantOK:  true,
+		},
+		{
+			name:    "nested block missing",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `{"total":60}`,
+		},
+		{
+			name:    "flat block missing",
+			extract: carthooks.PaginationAt(),
+			meta:    `{"other":1}`,
+		},
+		{
+			name:    "nil meta",
+			extract: carthooks.PaginationAt("pagination"),
+			meta:    `null`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.meta), &meta); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := tt.extract(meta)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("got %+v, %v; want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetAllUsesPaginationExtractor(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		meta    func(page int) string
+		extract carthooks.PaginationExtractor
+	}{
+		{"default", func(page int) string {
+			return fmt.Sprintf(`{"pagination":{"page":%d,"pageSize":2,"pageCount":3,"total":6}}`, page)
+		}, nil},
+		{"flat", func(page int) string {
+			return fmt.Sprintf(`{"page":%d,"pageSize":2,"pageCount":3,"total":6}`, page)
+		}, carthooks.PaginationAt()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				page, _ := strconv.Atoi(r.URL.Query().Get("pagination[page]"))
+				fmt.Fprintf(w, `{"data":[{"id":%d,"fields":{}},{"id":%d,"fields":{}}],"meta":%s}`, 2*page-1, 2*page, tt.meta(page))
+			}))
+			defer s.Close()
+			opts := []carthooks.Option{carthooks.WithBaseURL(s.URL)}
+			if tt.extract != nil {
+				opts = append(opts, carthooks.WithPaginationExtractor(tt.extract))
+			}
+			c := carthooks.NewClient("token", opts...)
+
+			items, err := c.Query(1, 2).Limit(2).GetAll(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Every page is full, so only the page count ends the loop
+			// without fetching a fourth, empty page.
+			if len(items) != 6 || requests != 3 {
+				t.Errorf("got %d items in %d requests, want 6 in 3", len(items), requests)
+			}
+
+			rsp, err := c.GetContext(context.Background(), s.URL+"/v1/apps/1/collections/2/items?pagination[page]=2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := rsp.Pagination()
+			if err != nil || p.Page != 2 || p.Total != 6 {
+				t.Errorf("got %+v, %v; want page 2 of 6 items", p, err)
+			}
+		})
+	}
+}
+
+func TestGetAllPagesPastClampedPageSize(t *testing.T) {
+	const total, maxSize = 250, 100
+	var sizes []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sizes = append(sizes, r.URL.Query().Get("pagination[pageSize]"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("pagination[page]"))
+		var data []map[string]interface{}
+		for id := (page-1)*maxSize + 1; id <= page*maxSize && id <= total; id++ {
+			data = append(data, map[string]interface{}{"id": id, "fields": map[string]interface{}{}})
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"data": data,
+			// The server clamps the page size and reports no page count.
+			"meta": map[string]interface{}{"pagination": map[string]interface{}{"page": page, "pageSize": maxSize}},
+		})
+		w.Write(body)
+	}))
+	defer s.Close()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL))
+
+	items, err := c.Query(1, 2).Limit(500).WithoutCount().GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != total || len(sizes) != 3 {
+		t.Errorf("got %d items in %d requests, want %d in 3", len(items), len(sizes), total)
+	}
+	if len(sizes) > 0 && sizes[0] != "500" {
+		t.Errorf("requested page size %s, want 500", sizes[0])
+	}
+}
@@ -0,0 +1,85 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// FieldOp is an operation on a field that the server applies atomically,
+// against the field's current value. Use it as a field value in PatchItem.
+type FieldOp struct {
+	op    string
+	value interface{}
+}
+
+// MarshalJSON encodes the operation as {"$op": value}.
+func (o FieldOp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{o.op: o.value})
+}
+
+// IncrementBy adds n to a number field. A negative n decrements it.
+func IncrementBy(n float64) FieldOp {
+	return FieldOp{op: "$inc", value: n}
+}
+
+// AppendValues appends values to an array field.
+func AppendValues(values ...interface{}) FieldOp {
+	return FieldOp{op: "$push", value: values}
+}
+
+// Unset removes a field from the item.
+func Unset() FieldOp {
+	return FieldOp{op: "$unset", value: true}
+}
+
+// PatchItem changes only the given fields of an item, leaving the others as
+// they are on the server, unlike UpdateItem which sends the item's data as a
+// whole. Values may be FieldOps, so that concurrent writers incrementing a
+// counter or appending to a list do not overwrite each other:
+//
+//	c.PatchItem(ctx, appID, collectionID, itemID, map[string]interface{}{
+//		"status": "shipped",
+//		"events": carthooks.IncrementBy(1),
+//		"log":    carthooks.AppendValues("shipped"),
+//	})
+//
+// Field codecs apply to plain values but not to the values of FieldOps.
+func (c *Client) PatchItem(ctx context.Context, appID, collectionID, itemID int, data map[string]interface{}, opts ...WriteOption) (*Response, error) {
+	o := newWriteOptions(opts)
+	plain := make(map[string]interface{}, len(data))
+	ops := map[string]interface{}{}
+	for field, value := range data {
+		if op, ok := value.(FieldOp); ok {
+			ops[field] = op
+		} else {
+			plain[field] = value
+		}
+	}
+	fields, err := c.encodeFields(plain)
+	if err != nil {
+		return nil, err
+	}
+	for field, op := range ops {
+		fields[field] = op
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d",
+		c.baseUrl, appID, collectionID, itemID)
+	return c.do(ctx, http.MethodPatch, urladdr, jsonBody(c.envelope(fields)), o.header)
+}
+
+// IncrementField atomically adds n to a number field of an item.
+func (c *Client) IncrementField(ctx context.Context, appID, collectionID, itemID int, field string, n float64) (*Response, error) {
+	return c.PatchItem(ctx, appID, collectionID, itemID, map[string]interface{}{field: IncrementBy(n)})
+}
+
+// AppendToArrayField atomically appends values to an array field of an item.
+func (c *Client) AppendToArrayField(ctx context.Context, appID, collectionID, itemID int, field string, values ...interface{}) (*Response, error) {
+	return c.PatchItem(ctx, appID, collectionID, itemID, map[string]interface{}{field: AppendValues(values...)})
+}
+
+// RemoveField removes a field from an item.
+func (c *Client) RemoveField(ctx context.Context, appID, collectionID, itemID int, field string) (*Response, error) {
+	return c.PatchItem(ctx, appID, collectionID, itemID, map[string]interface{}{field: Unset()})
+}
@@ -0,0 +1,125 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// TenantCredentials are the credentials a ClientPool uses for one tenant:
+// either a static access token or OAuth2 client credentials.
+type TenantCredentials struct {
+	AccessToken string
+
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// SecretProvider looks up the credentials of tenants, e.g. in a secret
+// manager.
+type SecretProvider interface {
+	Credentials(ctx context.Context, tenantID string) (TenantCredentials, error)
+}
+
+// SecretProviderFunc adapts a function to SecretProvider.
+type SecretProviderFunc func(ctx context.Context, tenantID string) (TenantCredentials, error)
+
+func (f SecretProviderFunc) Credentials(ctx context.Context, tenantID string) (TenantCredentials, error) {
+	return f(ctx, tenantID)
+}
+
+// StaticSecrets is a SecretProvider backed by a map from tenant IDs to
+// credentials.
+type StaticSecrets map[string]TenantCredentials
+
+func (s StaticSecrets) Credentials(_ context.Context, tenantID string) (TenantCredentials, error) {
+	creds, ok := s[tenantID]
+	if !ok {
+		return TenantCredentials{}, fmt.Errorf("carthooks: no credentials for tenant %q", tenantID)
+	}
+	return creds, nil
+}
+
+// ClientPool hands out one client per tenant, for backends serving many
+// Carthooks accounts. The clients are created on first use and kept; they
+// share one HTTP client, and so one connection pool, but each has its own
+// token, rate limiter, circuit breaker and caches. A CacheStore given with
+// WithResponseCache is shared too, but each tenant's entries are kept under
+// their own keys.
+//
+//	pool := carthooks.NewClientPool(secrets, carthooks.WithRetry(3, time.Second))
+//	items, err := pool.For(tenantID).Query(appID, collectionID).GetAll(ctx)
+type ClientPool struct {
+	secrets    SecretProvider
+	opts       []Option
+	httpClient *http.Client
+	err        error
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewClientPool returns a pool whose clients are configured by opts and
+// authenticate with the credentials secrets returns for their tenant.
+// Token options among opts are overridden.
+func NewClientPool(secrets SecretProvider, opts ...Option) *ClientPool {
+	shared := NewClient("", opts...)
+	return &ClientPool{
+		secrets:    secrets,
+		opts:       opts,
+		httpClient: shared.httpClient,
+		err:        shared.err,
+		clients:    map[string]*Client{},
+	}
+}
+
+// For returns the client of a tenant. Its credentials are looked up when it
+// first needs a token, and again after the API rejects the token, so
+// rotated secrets are picked up; a failed lookup fails the request.
+func (p *ClientPool) For(tenantID string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[tenantID]; ok {
+		return c
+	}
+	opts := append(append([]Option{}, p.opts...), p.tenantOption(tenantID))
+	c := NewClient("", opts...)
+	p.clients[tenantID] = c
+	return c
+}
+
+// Remove drops the client of a tenant, e.g. once the tenant is gone. A later
+// For creates a new one.
+func (p *ClientPool) Remove(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, tenantID)
+}
+
+// Err returns the configuration errors of the pool's options, as
+// Client.Err does.
+func (p *ClientPool) Err() error {
+	return p.err
+}
+
+func (p *ClientPool) tenantOption(tenantID string) Option {
+	return func(c *Client) {
+		c.pool = p
+		c.cacheNamespace = fmt.Sprintf("tenant:%q ", tenantID)
+		c.tokens.provider = func(ctx context.Context) (*Token, error) {
+			creds, err := p.secrets.Credentials(ctx, tenantID)
+			if err != nil {
+				return nil, fmt.Errorf("carthooks: credentials of tenant %q: %w", tenantID, err)
+			}
+			switch {
+			case creds.AccessToken != "":
+				return &Token{AccessToken: creds.AccessToken}, nil
+			case creds.ClientID != "":
+				return c.clientCredentialsToken(ctx, creds.ClientID, creds.ClientSecret, creds.Scopes)
+			}
+			return nil, fmt.Errorf("carthooks: tenant %q has no credentials", tenantID)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package carthooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestClientPoolSeparatesResponseCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"id":1,"fields":{"owner":%q}}}`, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+	pool := carthooks.NewClientPool(carthooks.StaticSecrets{
+		"a": {AccessToken: "token-a"},
+		"b": {AccessToken: "token-b"},
+	}, carthooks.WithBaseURL(srv.URL), carthooks.WithResponseCache(carthooks.NewMemoryCache(0), time.Minute))
+
+	for _, tenant := range []string{"a", "b", "a", "b"} {
+		item, err := pool.For(tenant).GetItemByIDContext(context.Background(), 1, 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "Bearer token-" + tenant; item.Fields["owner"] != want {
+			t.Errorf("tenant %s got item fetched with %v, want %s", tenant, item.Fields["owner"], want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package carthooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// knownOperators lists the filter operators the API understands.
+var knownOperators = map[string]bool{
+	OpEq: true, OpEqi: true, OpNe: true, OpNei: true,
+	OpLt: true, OpLte: true, OpGt: true, OpGte: true,
+	OpIn: true, OpNotIn: true, OpBetween: true,
+	OpContains: true, OpNotContains: true, OpContainsi: true, OpNotContainsi: true,
+	OpStartsWith: true, OpStartsWithi: true, OpEndsWith: true, OpEndsWithi: true,
+	OpNull: true, OpNotNull: true,
+}
+
+// QueryDefinition is the declarative form of a Query, e.g. a saved view
+// loaded from configuration.
+type QueryDefinition struct {
+	Filters []FilterDefinition `json:"filters,omitempty"`
+	Sort    []SortDefinition   `json:"sort,omitempty"`
+	Limit   int                `json:"limit,omitempty"`
+	Page    int                `json:"page,omitempty"`
+}
+
+// FilterDefinition is one filter condition. Value must be a string, number
+// or boolean, or a list of them for multi-value operators such as $in.
+type FilterDefinition struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// SortDefinition is one sort key. Direction defaults to ascending.
+type SortDefinition struct {
+	Field     string        `json:"field"`
+	Direction SortDirection `json:"direction,omitempty"`
+	Nulls     NullOrder     `json:"nulls,omitempty"`
+}
+
+// ParseQuery builds a query from a JSON QueryDefinition. Unknown keys are
+// rejected, as are definitions QueryFromDefinition rejects.
+func (c *Client) ParseQuery(appID, collectionID int, data []byte) (*Query, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	var def QueryDefinition
+	if err := dec.Decode(&def); err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	return c.QueryFromDefinition(appID, collectionID, def)
+}
+
+// QueryFromDefinition builds a query from def. An empty field, an unknown
+// operator, sort direction or null ordering, or a value that is neither a
+// scalar nor a list of scalars is reported as a *ValidationError with a zero
+// StatusCode, naming the offending filter. allowedFields, if given, restricts
+// the fields that may be filtered and sorted on.
+func (c *Client) QueryFromDefinition(appID, collectionID int, def QueryDefinition, allowedFields ...string) (*Query, error) {
+	allowed := map[string]bool{}
+	for _, f := range allowedFields {
+		allowed[f] = true
+	}
+	checkField := func(field string) error {
+		if field == "" {
+			return &ValidationError{Message: "missing field name"}
+		}
+		if len(allowed) > 0 && !allowed[field] {
+			return &ValidationError{Field: field, Message: "unknown field"}
+		}
+		return nil
+	}
+
+	q := c.Query(appID, collectionID)
+	for _, f := range def.Filters {
+		if err := checkField(f.Field); err != nil {
+			return nil, err
+		}
+		if err := validateFilter(f.Field, f.Operator); err != nil {
+			return nil, err
+		}
+		if list, ok := f.Value.([]interface{}); ok {
+			values := make([]string, len(list))
+			for i, v := range list {
+				value, err := filterValue(v)
+				if err != nil {
+					return nil, &ValidationError{Field: f.Field, Operator: f.Operator, Message: err.Error()}
+				}
+				values[i] = value
+			}
+			q.FilterValues(f.Field, f.Operator, values)
+			continue
+		}
+		value, err := filterValue(f.Value)
+		if err != nil {
+			return nil, &ValidationError{Field: f.Field, Operator: f.Operator, Message: err.Error()}
+		}
+		q.Filter(f.Field, f.Operator, value)
+	}
+	for _, s := range def.Sort {
+		if err := checkField(s.Field); err != nil {
+			return nil, err
+		}
+		if s.Direction != "" && s.Direction != Asc && s.Direction != Desc {
+			return nil, &ValidationError{Field: s.Field, Message: fmt.Sprintf("unknown sort direction %q", s.Direction)}
+		}
+		if s.Nulls != "" && s.Nulls != NullsFirst && s.Nulls != NullsLast {
+			return nil, &ValidationError{Field: s.Field, Message: fmt.Sprintf("unknown null ordering %q", s.Nulls)}
+		}
+		q.OrderBy(s.Field, s.Direction, s.Nulls)
+	}
+	if def.Limit < 0 || def.Page < 0 {
+		return nil, &ValidationError{Message: "limit and page must not be negative"}
+	}
+	if def.Limit > 0 {
+		q.Limit(def.Limit)
+	}
+	q.page = def.Page
+	return q, nil
+}
+
+// validateFilter checks a filter's operator against the operators the API
+// understands.
+func validateFilter(field, operator string) error {
+	if !knownOperators[operator] {
+		return &ValidationError{Field: field, Operator: operator, Message: "unknown operator"}
+	}
+	return nil
+}
+
+// filterValue formats a scalar filter value for the query string.
+func filterValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8, int16, int32, int64:
+		return strconv.FormatInt(reflect.ValueOf(v).Int(), 10), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return strconv.FormatUint(reflect.ValueOf(v).Uint(), 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case nil:
+		return "", fmt.Errorf("missing value")
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
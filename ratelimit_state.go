@@ -0,0 +1,104 @@
+package carthooks
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitState tracks the latest rate-limit budget reported by the API.
+type rateLimitState struct {
+	mu        sync.Mutex
+	known     bool
+	limit     int
+	remaining int
+	resetAt   time.Time
+	// retryUntil is the latest deadline asked for by a Retry-After header.
+	retryUntil time.Time
+}
+
+// RateLimit returns the rate-limit budget reported by the most recent
+// response carrying X-RateLimit-Remaining: the number of requests left and
+// when the budget resets. ok is false until such a response was seen.
+func (c *Client) RateLimit() (remaining int, resetAt time.Time, ok bool) {
+	s := &c.rateLimit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remaining, s.resetAt, s.known
+}
+
+// updateRateLimit records the rate-limit headers of a response. The reset
+// header may be a Unix timestamp or a number of seconds from now.
+func (c *Client) updateRateLimit(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	now := c.clock.Now()
+	var resetAt time.Time
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset > 1e9 {
+			resetAt = time.Unix(reset, 0)
+		} else {
+			resetAt = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	s := &c.rateLimit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.known = true
+	s.limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	s.remaining = remaining
+	s.resetAt = resetAt
+}
+
+// RateLimitStatus is a snapshot of the client's rate limiting.
+type RateLimitStatus struct {
+	// Known reports whether a response carrying X-RateLimit-Remaining was
+	// seen. Limit, Remaining and ResetAt are only meaningful if so.
+	Known bool
+	// Limit is the budget per window from X-RateLimit-Limit, or zero if the
+	// API did not send it.
+	Limit int
+	// Remaining is the number of requests left, counting down locally as
+	// requests are sent until the next response reports it again.
+	Remaining int
+	ResetAt   time.Time
+	// RetryAfter is what is left of a Retry-After wait; see Client.RetryAfter.
+	RetryAfter time.Duration
+	// Rate and Burst are the settings of the client's own limiter, or zero
+	// without one.
+	Rate  float64
+	Burst int
+}
+
+// RateLimitStatus returns the current rate-limit state of the client.
+func (c *Client) RateLimitStatus() RateLimitStatus {
+	st := RateLimitStatus{RetryAfter: c.RetryAfter()}
+	if c.limiter != nil {
+		st.Rate, st.Burst = c.limiter.rate, int(c.limiter.burst)
+	}
+	s := &c.rateLimit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.Known, st.Limit, st.Remaining, st.ResetAt = s.known, s.limit, s.remaining, s.resetAt
+	return st
+}
+
+// reserveBudget takes one request from the budget reported by the API and
+// returns how long to wait before sending it: until the reset if the budget
+// is used up, and zero if it is not or nothing is known about it.
+func (s *rateLimitState) reserveBudget(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.known || !s.resetAt.After(now) {
+		// Without a reset time, or once it passed, the budget is stale.
+		return 0
+	}
+	if s.remaining > 0 {
+		s.remaining--
+		return 0
+	}
+	return s.resetAt.Sub(now)
+}
@@ -0,0 +1,76 @@
+package carthooks_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestRecorderRedactsSecrets(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "file content")
+	}))
+	defer files.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			io.WriteString(w, `{"access_token":"live-access","refresh_token":"live-refresh","expires_in":3600}`)
+		default:
+			fmt.Fprintf(w, `{"data":{"id":3,"fields":{"seen":%q}}}`, r.Header.Get("Authorization"))
+		}
+	}))
+	defer api.Close()
+
+	fixture := filepath.Join(t.TempDir(), "fixture.json")
+	ctx := context.Background()
+	c := carthooks.NewClient("", carthooks.WithBaseURL(api.URL),
+		carthooks.WithClientCredentials("client", "live-secret"),
+		carthooks.WithRecorder(fixture, carthooks.RecorderRecord))
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	body, err := c.OpenFile(ctx, carthooks.Attachment{URL: files.URL + "/f.pdf?X-Amz-Signature=live-sig&X-Amz-Credential=live-cred&part=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"live-access", "live-refresh", "live-secret", "live-sig", "live-cred"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "part=1") {
+		t.Errorf("fixture lost the unsigned query parameter:\n%s", data)
+	}
+
+	api.Close()
+	files.Close()
+	replay := carthooks.NewClient("", carthooks.WithBaseURL(api.URL),
+		carthooks.WithClientCredentials("client", "live-secret"),
+		carthooks.WithRecorder(fixture, carthooks.RecorderReplay))
+	if _, err := replay.GetItemByIDContext(ctx, 1, 2, 3); err != nil {
+		t.Errorf("replaying item: %v", err)
+	}
+	body, err = replay.OpenFile(ctx, carthooks.Attachment{URL: files.URL + "/f.pdf?X-Amz-Signature=other-sig&X-Amz-Credential=other&part=1"})
+	if err != nil {
+		t.Fatalf("replaying file: %v", err)
+	}
+	content, _ := io.ReadAll(body)
+	body.Close()
+	if string(content) != "file content" {
+		t.Errorf("replayed file content %q", content)
+	}
+}
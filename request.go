@@ -0,0 +1,321 @@
+package carthooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// newRequest builds an API request carrying the standard headers and the
+// encoded, possibly compressed body. It also returns the payload as sent.
+func (c *Client) newRequest(ctx context.Context, method, url string, body requestBody, header http.Header) (*http.Request, []byte, error) {
+	var (
+		payload []byte
+		err     error
+	)
+	if body != nil {
+		if payload, err = body.encode(); err != nil {
+			return nil, nil, err
+		}
+	}
+	compressed := c.shouldCompress(len(payload))
+	if compressed {
+		if payload, err = gzipBytes(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Passing a *bytes.Reader lets NewRequest set ContentLength and GetBody,
+	// so the body is sent with its length and survives redirects.
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType())
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, nil, err
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		req.Header.Set(c.requestIDHeader, id)
+	}
+	if key, ok := IdempotencyKeyFromContext(ctx); ok {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	applyRequestOptions(ctx, req)
+	if payload == nil {
+		// A request without a body is signed as having an empty one.
+		payload = []byte{}
+	}
+	return req, payload, nil
+}
+
+// send performs req through the rate limiter, the concurrency limit, the
+// signer, the interceptors and the circuit breaker, noting any throttling
+// delay and connection reuse in info. req is signed with sign, if not nil,
+// only once those waits are over, so its timestamp is not stale when sent.
+// The request slot is released when the response body is closed.
+func (c *Client) send(ctx context.Context, req *http.Request, sign func(*http.Request) error, info *RequestInfo) (*http.Response, error) {
+	wait, err := c.throttle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info.ThrottleWait = wait
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sign != nil {
+		if err := sign(req); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	if err := c.intercept(req); err != nil {
+		release()
+		return nil, err
+	}
+	if c.breaker != nil {
+		if err := c.breaker.allow(c.clock.Now()); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	c.beforeSend(*info)
+	resp, err := c.roundTrip(c.traceConn(req, info))
+	if c.breaker != nil {
+		c.breaker.record(requestOutcome(ctx, resp, err), c.clock.Now())
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+	c.updateRateLimit(resp.Header)
+	c.updateRetryAfter(resp)
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// do performs an API request, retrying it as configured with WithRetry and
+// once with a fresh token after a 401 when a token provider is set. With a
+// response cache, GET requests are served from it and other requests
+// invalidate it.
+func (c *Client) do(ctx context.Context, method, url string, body requestBody, header http.Header) (*Response, error) {
+	if c.cache != nil {
+		if method == http.MethodGet {
+			return c.cachedDo(ctx, url, header)
+		}
+		defer c.invalidateCache(url)
+	}
+	return c.doWithRetry(ctx, method, url, body, header)
+}
+
+func (c *Client) doWithRetry(ctx context.Context, method, url string, body requestBody, header http.Header) (*Response, error) {
+	ctx, err := c.withIdempotencyKey(ctx, method, header)
+	if err != nil {
+		return nil, err
+	}
+	refreshed := false
+	if o := requestOptionsFrom(ctx); o != nil && o.token != "" {
+		// A token given for the call is not the client's to refresh.
+		refreshed = true
+	}
+	for attempt := 1; ; attempt++ {
+		rsp, err := c.doOnce(ctx, method, url, body, header, attempt)
+		if !refreshed && c.refreshOnUnauthorized(err) {
+			refreshed = true
+			continue
+		}
+		delay, ok := c.retryDelay(ctx, method, header, err, attempt)
+		if !ok {
+			return rsp, err
+		}
+		c.logger.Warn("carthooks: retrying request", "method", method, "route", routeOf(url),
+			"attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-c.clock.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// doOnce performs a single attempt of an API request.
+func (c *Client) doOnce(ctx context.Context, method, url string, body requestBody, header http.Header, attempt int) (rsp *Response, err error) {
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	ctx, endSpan := c.startSpan(ctx, method, url, attempt)
+	req, payload, err := c.newRequest(ctx, method, url, body, header)
+	if err != nil {
+		endSpan(RequestInfo{Method: method, URL: url, Route: routeOf(url), Attempt: attempt, Err: err})
+		return nil, err
+	}
+
+	start := c.clock.Now()
+	info := RequestInfo{Method: method, URL: url, Route: routeOf(url), RequestBytes: int64(len(payload)),
+		Operation: operationFromContext(ctx), Attempt: attempt}
+	defer func() {
+		info.Duration = c.clock.Now().Sub(start)
+		info.Err = err
+		endSpan(info)
+		if err != ErrCircuitOpen {
+			c.observe(info)
+		}
+	}()
+	resp, err := c.send(ctx, req, c.signWith(payload), &info)
+	if err != nil {
+		c.logger.Debug("carthooks request failed", "method", method, "route", routeOf(url),
+			"duration", c.clock.Now().Sub(start), "attempt", attempt, "error", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	info.StatusCode = resp.StatusCode
+	data, err := ioutil.ReadAll(resp.Body)
+	info.ResponseBytes = int64(len(data))
+	if err != nil {
+		return nil, err
+	}
+
+	result := Response{}
+	decodeErr := c.decodeResponse(data, &result)
+	info.TraceId = result.TraceId
+	recordTraceID(ctx, result.TraceId)
+	c.logger.Debug("carthooks request", "method", method, "route", routeOf(url),
+		"status", resp.StatusCode, "duration", c.clock.Now().Sub(start),
+		"trace_id", result.TraceId, "attempt", attempt)
+
+	if err := c.checkStatus(resp, &result, data); err != nil {
+		return nil, err
+	}
+	if decodeErr != nil && len(data) > 0 {
+		return nil, decodeErr
+	}
+
+	if result.Error != nil {
+		return nil, newAPIError(resp.StatusCode, &result, data)
+	}
+	result.RequestID = resp.Header.Get(c.requestIDHeader)
+	result.ETag = resp.Header.Get("ETag")
+	result.Header = resp.Header
+	result.client = c
+
+	return &result, nil
+}
+
+// checkStatus maps a non-2xx response to an error. result is the decoded
+// body and body the raw one.
+func (c *Client) checkStatus(resp *http.Response, result *Response, body []byte) error {
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return ErrNotModified
+	}
+	apiErr := newAPIError(resp.StatusCode, result, body)
+	switch resp.StatusCode {
+	case http.StatusLocked:
+		return fmt.Errorf("%w: %w", ErrLocked, apiErr)
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return &ConflictError{StatusCode: resp.StatusCode, CurrentVersion: resp.Header.Get("ETag"), err: apiErr}
+	case http.StatusRequestEntityTooLarge:
+		limit, _ := strconv.ParseInt(resp.Header.Get("X-Max-Content-Length"), 10, 64)
+		return &PayloadTooLargeError{Limit: limit, err: apiErr}
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		d, _ := parseRetryAfter(resp.Header.Get("Retry-After"), c.clock.Now())
+		return &RetryAfterError{StatusCode: resp.StatusCode, RetryAfter: d, err: apiErr}
+	default:
+		return apiErr
+	}
+}
+
+// stream performs a request whose successful response body is passed to the
+// caller unparsed, e.g. a CSV export. Error responses carry the usual JSON
+// envelope and are turned into errors. The caller must close the body.
+func (c *Client) stream(ctx context.Context, method, url string, body requestBody, header http.Header) (*http.Response, error) {
+	ctx, cancel := withRequestTimeout(ctx)
+	resp, err := c.openStream(ctx, method, url, body, header)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
+
+func (c *Client) openStream(ctx context.Context, method, url string, body requestBody, header http.Header) (*http.Response, error) {
+	ctx, endSpan := c.startSpan(ctx, method, url, 1)
+	req, payload, err := c.newRequest(ctx, method, url, body, header)
+	if err != nil {
+		endSpan(RequestInfo{Method: method, URL: url, Route: routeOf(url), Attempt: 1, Err: err})
+		return nil, err
+	}
+
+	start := c.clock.Now()
+	info := RequestInfo{Method: method, URL: url, Route: routeOf(url), RequestBytes: int64(len(payload)),
+		Operation: operationFromContext(ctx), Attempt: 1}
+	resp, err := c.send(ctx, req, c.signWith(payload), &info)
+	if err != nil {
+		info.Duration, info.Err = c.clock.Now().Sub(start), err
+		endSpan(info)
+		if err != ErrCircuitOpen {
+			c.observe(info)
+		}
+		return nil, err
+	}
+	info.StatusCode = resp.StatusCode
+
+	if resp.StatusCode != http.StatusOK || isJSON(resp.Header.Get("Content-Type")) {
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+		result := Response{}
+		_ = c.decodeResponse(data, &result)
+		info.Duration, info.ResponseBytes, info.TraceId = c.clock.Now().Sub(start), int64(len(data)), result.TraceId
+		recordTraceID(ctx, result.TraceId)
+		err := c.checkStatus(resp, &result, data)
+		if err == nil && result.Error != nil {
+			err = newAPIError(resp.StatusCode, &result, data)
+		}
+		if err == nil {
+			err = fmt.Errorf("%w to a %s request", errUnexpectedJSON, req.Header.Get("Accept"))
+		}
+		info.Err = err
+		endSpan(info)
+		c.observe(info)
+		return nil, err
+	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, report: func(n int64) {
+		info.Duration, info.ResponseBytes = c.clock.Now().Sub(start), n
+		endSpan(info)
+		c.observe(info)
+	}}
+	return resp, nil
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
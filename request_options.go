@@ -0,0 +1,119 @@
+package carthooks
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOption customizes the requests made with a context; see
+// ContextWithRequestOptions.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header  http.Header
+	query   url.Values
+	timeout time.Duration
+	token   string
+	// cacheTTL overrides the response cache TTL; negative skips the cache.
+	cacheTTL time.Duration
+}
+
+type requestOptionsKey struct{}
+
+// ContextWithRequestOptions returns a copy of ctx whose requests are
+// customized by opts, on top of any options already in ctx. Every operation
+// takes a context, so this is how options are passed to a single call:
+//
+//	ctx := carthooks.ContextWithRequestOptions(ctx,
+//		carthooks.WithHeader("X-Tenant", tenant),
+//		carthooks.WithRequestTimeout(5*time.Second))
+//	item, err := c.GetItemByIDContext(ctx, appID, collectionID, itemID)
+//
+// The options apply to every request the call makes, including retries and
+// the pages of a listing.
+func ContextWithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	o := requestOptions{header: http.Header{}, query: url.Values{}}
+	if prev := requestOptionsFrom(ctx); prev != nil {
+		o.timeout, o.token, o.cacheTTL = prev.timeout, prev.token, prev.cacheTTL
+		for key, values := range prev.header {
+			o.header[key] = append([]string(nil), values...)
+		}
+		for key, values := range prev.query {
+			o.query[key] = append([]string(nil), values...)
+		}
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, &o)
+}
+
+func requestOptionsFrom(ctx context.Context) *requestOptions {
+	o, _ := ctx.Value(requestOptionsKey{}).(*requestOptions)
+	return o
+}
+
+// WithHeader sets a header on the request, replacing any the client sets
+// itself except Authorization; see WithAccessToken for that.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithQueryParam adds a query parameter to the request URL.
+func WithQueryParam(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.query.Add(key, value)
+	}
+}
+
+// WithRequestTimeout limits how long each request may take, like WithTimeout
+// but for single calls. For streamed responses it covers reading the body.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// WithAccessToken sends token instead of the client's own access token. A
+// request rejected with 401 is then not retried with a refreshed token.
+func WithAccessToken(token string) RequestOption {
+	return func(o *requestOptions) {
+		o.token = token
+	}
+}
+
+// withRequestTimeout applies the timeout of the context's request options,
+// if any, to ctx.
+func withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o := requestOptionsFrom(ctx); o != nil && o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// applyRequestOptions adds the headers and query parameters of the
+// context's request options to req.
+func applyRequestOptions(ctx context.Context, req *http.Request) {
+	o := requestOptionsFrom(ctx)
+	if o == nil {
+		return
+	}
+	for key, values := range o.header {
+		if key == "Authorization" {
+			// Credentials for the call are set with WithAccessToken.
+			continue
+		}
+		req.Header[key] = values
+	}
+	if len(o.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range o.query {
+			query[key] = append(query[key], values...)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+}
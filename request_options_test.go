@@ -0,0 +1,38 @@
+package carthooks_test
+
+import (
+	"context"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestWithHeaderKeepsAuthorization(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	item := s.AddItem(1, 2, map[string]interface{}{"title": "a"})
+	c := s.Client()
+
+	ctx := carthooks.ContextWithRequestOptions(context.Background(),
+		carthooks.WithHeader("Authorization", "Bearer other"),
+		carthooks.WithHeader("X-Tenant", "acme"))
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, item.ID); err != nil {
+		t.Fatal(err)
+	}
+	ctx = carthooks.ContextWithRequestOptions(context.Background(), carthooks.WithAccessToken("call-token"))
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, item.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := s.Requests()
+	if got := requests[0].Header.Get("Authorization"); got != "Bearer test-token" {
+		t.Errorf("Authorization with WithHeader = %q, want the client's token", got)
+	}
+	if got := requests[0].Header.Get("X-Tenant"); got != "acme" {
+		t.Errorf("X-Tenant = %q, want acme", got)
+	}
+	if got := requests[1].Header.Get("Authorization"); got != "Bearer call-token" {
+		t.Errorf("Authorization with WithAccessToken = %q, want the call's token", got)
+	}
+}
@@ -0,0 +1,31 @@
+package carthooks
+
+import "context"
+
+const defaultRequestIDHeader = "X-Request-Id"
+
+type contextKey string
+
+// RequestIDKey is the context key holding the correlation ID sent with each
+// outbound request. Values stored under it must be strings; ContextWithRequestID
+// is the usual way to set it.
+const RequestIDKey = contextKey("carthooks.request_id")
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(RequestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestIDHeader sets the header used to send the context request ID and
+// to read back the server-assigned one. The default is X-Request-Id.
+func WithRequestIDHeader(name string) Option {
+	return func(c *Client) {
+		c.requestIDHeader = name
+	}
+}
@@ -0,0 +1,234 @@
+package carthooks
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores cached API responses for WithResponseCache. Keys are
+// request URLs, prefixed with the tenant for the clients of a ClientPool.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	// DeletePrefix removes every entry whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// WithResponseCache serves GET requests, such as item reads and query pages,
+// from store while they are younger than ttl. Writes made through the client
+// drop the cached responses of the collection they touch; writes made by
+// anyone else show up only once the entries expire. Use WithCacheTTL and
+// WithNoCache to change this for single calls, and NewMemoryCache for an
+// in-process store.
+//
+// Calls made with WithAccessToken or WithHeader bypass the cache, since
+// their responses may depend on who asks.
+func WithResponseCache(store CacheStore, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache, c.cacheTTL = store, ttl
+	}
+}
+
+// WithCacheTTL caches the responses of a call for ttl instead of the TTL
+// given to WithResponseCache.
+func WithCacheTTL(ttl time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithNoCache makes a call bypass the response cache, neither reading from
+// nor adding to it.
+func WithNoCache() RequestOption {
+	return func(o *requestOptions) {
+		o.cacheTTL = -1
+	}
+}
+
+// cachedResponse is the stored form of a Response.
+type cachedResponse struct {
+	Data      json.RawMessage        `json:"data"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+	TraceId   string                 `json:"trace_id,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	ETag      string                 `json:"etag,omitempty"`
+}
+
+// cachedDo performs a GET request through the response cache.
+func (c *Client) cachedDo(ctx context.Context, rawURL string, header http.Header) (*Response, error) {
+	ttl := c.cacheTTL
+	key := c.cacheKey(rawURL)
+	if o := requestOptionsFrom(ctx); o != nil {
+		if o.cacheTTL != 0 {
+			ttl = o.cacheTTL
+		}
+		if o.token != "" || len(o.header) > 0 {
+			ttl = -1
+		}
+		if len(o.query) > 0 {
+			key += "#" + o.query.Encode()
+		}
+	}
+	// Conditional reads are the caller's own caching.
+	if ttl <= 0 || header.Get("If-None-Match") != "" {
+		return c.doWithRetry(ctx, http.MethodGet, rawURL, nil, header)
+	}
+	if data, ok := c.cacheGet(key); ok {
+		var cached cachedResponse
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &Response{Data: cached.Data, Meta: cached.Meta, TraceId: cached.TraceId,
+				RequestID: cached.RequestID, ETag: cached.ETag, Header: http.Header{}, client: c}, nil
+		}
+	}
+	rsp, err := c.doWithRetry(ctx, http.MethodGet, rawURL, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(cachedResponse{Data: rsp.Data, Meta: rsp.Meta, TraceId: rsp.TraceId,
+		RequestID: rsp.RequestID, ETag: rsp.ETag})
+	if err == nil {
+		c.cacheSet(key, data, ttl)
+	}
+	return rsp, nil
+}
+
+// clockedCacheStore is implemented by stores that can expire entries by the
+// client's Clock.
+type clockedCacheStore interface {
+	getAt(key string, now time.Time) ([]byte, bool)
+	setAt(key string, value []byte, now time.Time, ttl time.Duration)
+}
+
+func (c *Client) cacheGet(key string) ([]byte, bool) {
+	if s, ok := c.cache.(clockedCacheStore); ok {
+		return s.getAt(key, c.clock.Now())
+	}
+	return c.cache.Get(key)
+}
+
+func (c *Client) cacheSet(key string, value []byte, ttl time.Duration) {
+	if s, ok := c.cache.(clockedCacheStore); ok {
+		s.setAt(key, value, c.clock.Now(), ttl)
+		return
+	}
+	c.cache.Set(key, value, ttl)
+}
+
+// cacheKey returns the key of a URL: the URL with a slash ending its path, so
+// that the prefix of a collection does not also match collections whose IDs
+// start with the same digits.
+func (c *Client) cacheKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.cacheNamespace + rawURL
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+	return c.cacheNamespace + u.String()
+}
+
+// invalidateCache drops the cached responses of the collection, or else the
+// app, that rawURL belongs to.
+func (c *Client) invalidateCache(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	segments := strings.Split(u.Path, "/")
+	end := len(segments)
+	for i := len(segments) - 2; i >= 0; i-- {
+		if segments[i] == "collections" || segments[i] == "apps" {
+			end = i + 2
+			break
+		}
+	}
+	u.Path, u.RawQuery = strings.Join(segments[:end], "/"), ""
+	c.cache.DeletePrefix(c.cacheKey(u.String()))
+}
+
+// MemoryCache is an in-memory CacheStore that evicts the least recently
+// used entries beyond its capacity. Entries expire by the Clock of the client
+// that stores them.
+type MemoryCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to maxEntries responses,
+// or 1000 if maxEntries is zero or less.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{max: maxEntries, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	return m.getAt(key, time.Now())
+}
+
+func (m *MemoryCache) getAt(key string, now time.Time) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !now.Before(e.expires) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return e.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.setAt(key, value, time.Now(), ttl)
+}
+
+func (m *MemoryCache) setAt(key string, value []byte, now time.Time, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &memoryEntry{key: key, value: value, expires: now.Add(ttl)}
+	if el, ok := m.entries[key]; ok {
+		el.Value = e
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(e)
+	for m.order.Len() > m.max {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, el := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			m.order.Remove(el)
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package carthooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// countingServer answers every request with an item and counts GETs by path.
+func countingServer(t *testing.T) (*httptest.Server, func(path string) int) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			hits[r.URL.Path]++
+			mu.Unlock()
+		}
+		fmt.Fprint(w, `{"data":{"id":1,"fields":{}}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+}
+
+func TestResponseCacheExpiresByClientClock(t *testing.T) {
+	srv, hits := countingServer(t)
+	clock := newFakeClock()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL), carthooks.WithClock(clock),
+		carthooks.WithResponseCache(carthooks.NewMemoryCache(0), time.Minute))
+	ctx := context.Background()
+	path := "/v1/apps/1/collections/2/items/3"
+
+	c.GetItemByIDContext(ctx, 1, 2, 3)
+	c.GetItemByIDContext(ctx, 1, 2, 3)
+	if n := hits(path); n != 1 {
+		t.Fatalf("got %d requests before expiry, want 1", n)
+	}
+	clock.Advance(2 * time.Minute)
+	c.GetItemByIDContext(ctx, 1, 2, 3)
+	if n := hits(path); n != 2 {
+		t.Fatalf("got %d requests after expiry, want 2", n)
+	}
+}
+
+func TestResponseCacheBypassedForPerCallIdentity(t *testing.T) {
+	srv, hits := countingServer(t)
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL),
+		carthooks.WithResponseCache(carthooks.NewMemoryCache(0), time.Minute))
+	path := "/v1/apps/1/collections/2/items/3"
+
+	c.GetItemByIDContext(context.Background(), 1, 2, 3)
+	for _, opt := range []carthooks.RequestOption{
+		carthooks.WithAccessToken("other"),
+		carthooks.WithHeader("X-Tenant", "other"),
+	} {
+		ctx := carthooks.ContextWithRequestOptions(context.Background(), opt)
+		c.GetItemByIDContext(ctx, 1, 2, 3)
+		c.GetItemByIDContext(ctx, 1, 2, 3)
+	}
+	if n := hits(path); n != 5 {
+		t.Errorf("got %d requests, want 5: calls with their own identity must not use the cache", n)
+	}
+}
+
+func TestResponseCacheInvalidatesOnlyWrittenCollection(t *testing.T) {
+	srv, hits := countingServer(t)
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL),
+		carthooks.WithResponseCache(carthooks.NewMemoryCache(0), time.Minute))
+	ctx := context.Background()
+
+	c.GetItemByIDContext(ctx, 1, 2, 3)
+	c.GetItemByIDContext(ctx, 1, 20, 3)
+	if _, err := c.UpdateItemContext(ctx, 1, 2, 3, map[string]interface{}{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	c.GetItemByIDContext(ctx, 1, 2, 3)
+	c.GetItemByIDContext(ctx, 1, 20, 3)
+	if n := hits("/v1/apps/1/collections/2/items/3"); n != 2 {
+		t.Errorf("collection 2: got %d requests, want 2", n)
+	}
+	if n := hits("/v1/apps/1/collections/20/items/3"); n != 1 {
+		t.Errorf("collection 20: got %d requests, want 1", n)
+	}
+}
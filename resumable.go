@@ -0,0 +1,390 @@
+package carthooks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultPartSize is the part size of UploadResumable unless
+	// WithPartSize is given.
+	DefaultPartSize = 8 << 20
+
+	// maxPartAttempts bounds the attempts to upload one part.
+	maxPartAttempts = 3
+)
+
+// WithPartSize sets the size of the parts UploadResumable splits a file
+// into. The server may impose another size when the upload starts.
+func WithPartSize(n int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.partSize = n
+	}
+}
+
+// WithUploadConcurrency bounds the parts UploadResumable uploads at once,
+// DefaultConcurrency by default. Each part in flight is held in memory.
+func WithUploadConcurrency(n int) UploadOption {
+	return func(o *uploadOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithUploadBandwidth limits UploadResumable to about bytesPerSecond, shared
+// by the parts in flight. Parts wait for their whole size before they are
+// sent, so the limit holds on average rather than for every second.
+func WithUploadBandwidth(bytesPerSecond int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.bandwidth = bytesPerSecond
+	}
+}
+
+// WithUploadStore makes UploadResumable record its progress in store under
+// key, so that an upload interrupted by a failure or a restart continues
+// with the parts it lacks when it is started again with the same key and
+// file. The record is deleted once the upload completes.
+func WithUploadStore(store UploadStore, key string) UploadOption {
+	return func(o *uploadOptions) {
+		o.store, o.storeKey = store, key
+	}
+}
+
+// UploadState is the progress of a resumable upload as kept in an
+// UploadStore.
+type UploadState struct {
+	UploadID string `json:"uploadId"`
+	FileID   string `json:"fileId,omitempty"`
+	Size     int64  `json:"size"`
+	PartSize int64  `json:"partSize"`
+	// Parts maps the numbers of the uploaded parts, starting at 1, to the
+	// ETags the server returned for them.
+	Parts map[int]string `json:"parts"`
+}
+
+// UploadStore keeps the state of resumable uploads between runs.
+type UploadStore interface {
+	// Load returns the state saved under key, or nil if there is none.
+	Load(key string) (*UploadState, error)
+	Save(key string, state *UploadState) error
+	Delete(key string) error
+}
+
+// DirUploadStore is an UploadStore keeping each state as a JSON file in a
+// directory.
+type DirUploadStore string
+
+func (d DirUploadStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(string(d), hex.EncodeToString(sum[:])+".json")
+}
+
+func (d DirUploadStore) Load(key string) (*UploadState, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &UploadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (d DirUploadStore) Save(key string, state *UploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	// Write and rename, so a crash never leaves a truncated state behind.
+	tmp := d.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.path(key))
+}
+
+func (d DirUploadStore) Delete(key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// UploadResumable uploads a file of size bytes read from r in parts, for
+// files too large to send in one request. Parts are uploaded concurrently,
+// each retried on transport errors and 429 or 5xx responses, and the upload
+// is finalized once all of them arrived. Each part carries its SHA-256 for
+// the server to check; UploadResult.SHA256 is left empty, as the file is
+// never read in order.
+//
+// Without WithUploadStore a failed upload is aborted. With it, the upload is
+// kept on the server and resumed by the next call with the same key.
+// WithUploadProgress reports the bytes of the completed parts, including
+// those uploaded by an earlier run.
+func (c *Client) UploadResumable(ctx context.Context, r io.ReaderAt, size int64, filename, contentType string, opts ...UploadOption) (*UploadResult, error) {
+	ctx = withOperation(ctx, "UploadResumable")
+	o := uploadOptions{partSize: DefaultPartSize, concurrency: DefaultConcurrency}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.partSize <= 0 {
+		o.partSize = DefaultPartSize
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = DefaultConcurrency
+	}
+
+	var state *UploadState
+	if o.store != nil {
+		saved, err := o.store.Load(o.storeKey)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil && saved.Size == size && saved.PartSize > 0 {
+			state = saved
+		}
+	}
+	if state == nil {
+		started, err := c.startUpload(ctx, filename, contentType, size, o.partSize)
+		if err != nil {
+			return nil, err
+		}
+		state = started
+	}
+	if state.Parts == nil {
+		state.Parts = map[int]string{}
+	}
+
+	if err := c.uploadParts(ctx, r, state, o); err != nil {
+		if o.store == nil && ctx.Err() == nil {
+			if abortErr := c.abortUpload(ctx, state.UploadID); abortErr != nil {
+				c.logger.Warn("carthooks: aborting upload failed", "upload", state.UploadID, "error", abortErr)
+			}
+		}
+		return nil, err
+	}
+	result, err := c.completeUpload(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	result.Name, result.ContentType, result.Size = filename, contentType, size
+	if o.store != nil {
+		if err := o.store.Delete(o.storeKey); err != nil {
+			c.logger.Warn("carthooks: deleting upload state failed", "upload", state.UploadID, "error", err)
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) startUpload(ctx context.Context, filename, contentType string, size, partSize int64) (*UploadState, error) {
+	urladdr := fmt.Sprintf("%s/v1/uploads/multipart", c.baseUrl)
+	rsp, err := c.do(ctx, http.MethodPost, urladdr, jsonBody{
+		"name": filename, "contentType": contentType, "size": size, "partSize": partSize,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	state := &UploadState{}
+	if err := rsp.Bind(state); err != nil {
+		return nil, err
+	}
+	if state.UploadID == "" {
+		return nil, errors.New("carthooks: upload did not return an upload ID")
+	}
+	if state.PartSize <= 0 {
+		state.PartSize = partSize
+	}
+	state.Size = size
+	return state, nil
+}
+
+// uploadParts uploads the parts missing from state, saving state after each
+// one when a store is set. The first failure stops the parts not yet begun.
+func (c *Client) uploadParts(ctx context.Context, r io.ReaderAt, state *UploadState, o uploadOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var bucket *tokenBucket
+	if o.bandwidth > 0 {
+		rate := float64(o.bandwidth)
+		bucket = &tokenBucket{rate: rate, burst: rate, tokens: rate}
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, o.concurrency)
+		parts    = int((state.Size + state.PartSize - 1) / state.PartSize)
+		sent     int64
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	partLength := func(n int) int64 {
+		offset := int64(n-1) * state.PartSize
+		if offset+state.PartSize > state.Size {
+			return state.Size - offset
+		}
+		return state.PartSize
+	}
+	// The parts still to send are listed before any is sent: state.Parts
+	// and sent are only touched under mu from then on.
+	var missing []int
+	for n := 1; n <= parts; n++ {
+		if _, done := state.Parts[n]; done {
+			sent += partLength(n)
+		} else {
+			missing = append(missing, n)
+		}
+	}
+	for _, n := range missing {
+		offset, length := int64(n-1)*state.PartSize, partLength(n)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(n int, offset, length int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			data := make([]byte, length)
+			// ReadAt may report io.EOF along with the last full part.
+			if read, err := r.ReadAt(data, offset); int64(read) < length {
+				fail(fmt.Errorf("carthooks: reading part %d: %w", n, err))
+				return
+			}
+			if bucket != nil {
+				select {
+				case <-c.clock.After(bucket.reserveN(c.clock.Now(), float64(length))):
+				case <-ctx.Done():
+					fail(ctx.Err())
+					return
+				}
+			}
+			etag, err := c.uploadPart(ctx, state.UploadID, n, data)
+			if err != nil {
+				fail(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			state.Parts[n] = etag
+			sent += length
+			if o.store != nil {
+				if err := o.store.Save(o.storeKey, state); err != nil {
+					c.logger.Warn("carthooks: saving upload state failed", "upload", state.UploadID, "error", err)
+				}
+			}
+			if o.progress != nil {
+				o.progress(sent, state.Size)
+			}
+		}(n, offset, length)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// uploadPart sends one part and returns its ETag. On top of the client's
+// own retries, which only cover responses, it retries transport errors, and
+// 429 and 5xx responses when the client does not retry.
+func (c *Client) uploadPart(ctx context.Context, uploadID string, n int, data []byte) (string, error) {
+	urladdr := fmt.Sprintf("%s/v1/uploads/multipart/%s/parts/%d", c.baseUrl, uploadID, n)
+	sum := sha256.Sum256(data)
+	header := http.Header{"X-Checksum-Sha256": {hex.EncodeToString(sum[:])}}
+	backoff := ExponentialBackoff(DefaultRetryBaseDelay, maxRetryDelay)
+	for attempt := 1; ; attempt++ {
+		rsp, err := c.doWithRetry(ctx, http.MethodPut, urladdr, rawBody{data: data, typ: "application/octet-stream"}, header)
+		if err == nil {
+			var part struct {
+				ETag string `json:"etag"`
+			}
+			if rsp.Bind(&part) == nil && part.ETag != "" {
+				return part.ETag, nil
+			}
+			return rsp.ETag, nil
+		}
+		if attempt >= maxPartAttempts || ctx.Err() != nil || !c.retryablePart(err) {
+			return "", fmt.Errorf("carthooks: uploading part %d: %w", n, err)
+		}
+		c.logger.Warn("carthooks: retrying upload part", "upload", uploadID, "part", n, "attempt", attempt, "error", err)
+		select {
+		case <-c.clock.After(backoff(attempt)):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// retryablePart reports whether a failed part upload is worth another
+// attempt.
+func (c *Client) retryablePart(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, ErrCircuitOpen)
+	}
+	if c.maxAttempts > 1 {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+}
+
+func (c *Client) completeUpload(ctx context.Context, state *UploadState) (*UploadResult, error) {
+	numbers := make([]int, 0, len(state.Parts))
+	for n := range state.Parts {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	parts := make([]interface{}, len(numbers))
+	for i, n := range numbers {
+		parts[i] = map[string]interface{}{"number": n, "etag": state.Parts[n]}
+	}
+	urladdr := fmt.Sprintf("%s/v1/uploads/multipart/%s/complete", c.baseUrl, state.UploadID)
+	rsp, err := c.do(ctx, http.MethodPost, urladdr, jsonBody{"parts": parts}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var completed struct {
+		ID string `json:"id"`
+	}
+	if err := rsp.Bind(&completed); err != nil {
+		return nil, err
+	}
+	result := &UploadResult{FileID: completed.ID}
+	if result.FileID == "" {
+		result.FileID = state.FileID
+	}
+	if result.FileID == "" {
+		return nil, errors.New("carthooks: upload did not return a file ID")
+	}
+	return result, nil
+}
+
+func (c *Client) abortUpload(ctx context.Context, uploadID string) error {
+	urladdr := fmt.Sprintf("%s/v1/uploads/multipart/%s", c.baseUrl, uploadID)
+	_, err := c.do(ctx, http.MethodDelete, urladdr, nil, nil)
+	return err
+}
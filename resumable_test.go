@@ -0,0 +1,71 @@
+package carthooks_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestUploadResumableResumesMissingParts(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdefghij"), 10) // 200 bytes, 20 parts of 10
+	var (
+		mu       sync.Mutex
+		received = map[int][]byte{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1/uploads/multipart/u1")
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "/parts/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(path, "/parts/"))
+			data, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received[n] = data
+			mu.Unlock()
+			fmt.Fprintf(w, `{"data":{"etag":"e%d"}}`, n)
+		case path == "/complete":
+			io.WriteString(w, `{"data":{"id":"file-1"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	store := carthooks.DirUploadStore(t.TempDir())
+	saved := &carthooks.UploadState{UploadID: "u1", Size: int64(len(content)), PartSize: 10,
+		Parts: map[int]string{1: "e1", 3: "e3", 20: "e20"}}
+	if err := store.Save("report", saved); err != nil {
+		t.Fatal(err)
+	}
+
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL))
+	res, err := c.UploadResumable(context.Background(), bytes.NewReader(content), int64(len(content)),
+		"report.bin", "application/octet-stream",
+		carthooks.WithPartSize(10), carthooks.WithUploadConcurrency(8), carthooks.WithUploadStore(store, "report"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FileID != "file-1" {
+		t.Errorf("FileID = %q", res.FileID)
+	}
+	if len(received) != 17 {
+		t.Errorf("got %d parts, want the 17 missing ones", len(received))
+	}
+	for n, data := range received {
+		if n == 1 || n == 3 || n == 20 {
+			t.Errorf("part %d was sent again", n)
+		}
+		if want := content[(n-1)*10 : n*10]; !bytes.Equal(data, want) {
+			t.Errorf("part %d = %q, want %q", n, data, want)
+		}
+	}
+}
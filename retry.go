@@ -0,0 +1,237 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxRetryDelay caps the exponential backoff between retries. A longer
+// Retry-After sent by the server is still honored.
+const maxRetryDelay = 30 * time.Second
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// ContextWithIdempotencyKey returns a copy of ctx carrying an idempotency
+// key, sent as the Idempotency-Key header on every request made with ctx,
+// including each retry. It lets WithRetry retry POST requests, which it
+// otherwise never retries.
+//
+// The key only prevents duplicates where the server honors the header;
+// endpoints that do not simply ignore it. Use one key per logical write.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in ctx, if any.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// WithIdempotencyKeys makes the client give every mutating request (POST,
+// PUT, PATCH or DELETE) without an idempotency key a fresh random one, reused
+// across its retries.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) {
+		c.autoIdempotencyKeys = true
+	}
+}
+
+// withIdempotencyKey adds a generated key to ctx for a mutating request
+// without one, if WithIdempotencyKeys is set.
+func (c *Client) withIdempotencyKey(ctx context.Context, method string, header http.Header) (context.Context, error) {
+	if !c.autoIdempotencyKeys || method == http.MethodGet || method == http.MethodHead || header.Get(idempotencyKeyHeader) != "" {
+		return ctx, nil
+	}
+	if _, ok := IdempotencyKeyFromContext(ctx); ok {
+		return ctx, nil
+	}
+	key, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	return ContextWithIdempotencyKey(ctx, key), nil
+}
+
+// WithRetry retries requests that fail with 429, 500, 502, 503 or 504, or
+// with a network error or the timeout of WithRequestTimeout, up to
+// maxAttempts attempts in total. The wait before a retry is the response's
+// Retry-After if present, and otherwise doubles from baseDelay on each
+// attempt, up to 30 seconds. Retries stop early when the context is done.
+//
+// Only idempotent requests (GET, PUT, DELETE) are retried, and POSTs that
+// carry an idempotency key (see ContextWithIdempotencyKey). Retries are off
+// by default. WithBackoff and WithRetryJitter change how the wait is computed
+// when the response has no Retry-After.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.retryBase = baseDelay
+	}
+}
+
+// retryDelay reports whether a request that failed with err on the given
+// attempt is to be retried, and after what delay.
+func (c *Client) retryDelay(ctx context.Context, method string, header http.Header, err error, attempt int) (time.Duration, bool) {
+	if err == nil || attempt >= c.maxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+	if !idempotent(method) && header.Get(idempotencyKeyHeader) == "" {
+		if _, ok := IdempotencyKeyFromContext(ctx); !ok {
+			return 0, false
+		}
+	}
+	// A network error, including the timeout of a single attempt, counts as
+	// no response, as in RetryMiddleware.
+	var status int
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.StatusCode
+	case !transportError(err):
+		return 0, false
+	}
+	var retryAfter time.Duration
+	var raErr *RetryAfterError
+	if errors.As(err, &raErr) {
+		retryAfter = raErr.RetryAfter
+	}
+	backoff := c.backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(c.retryBase, maxRetryDelay)
+	}
+	p := RetryPolicy{MaxAttempts: c.maxAttempts, Backoff: backoff, Jitter: c.retryJitter}
+	return p.delay(attempt, status, retryAfter)
+}
+
+// transportError reports whether err means the request or its response was
+// lost on the way, rather than refused or misunderstood.
+func transportError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// delay reports whether an attempt that failed with status, or with no
+// response if status is 0, is to be retried under p, and after what delay.
+// A positive retryAfter sent by the server replaces the backoff.
+func (p RetryPolicy) delay(attempt, status int, retryAfter time.Duration) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	switch status {
+	case 0, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+	if retryAfter > 0 {
+		return retryAfter, true
+	}
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(DefaultRetryBaseDelay, maxRetryDelay)
+	}
+	delay := backoff(attempt)
+	if jitter := clampJitter(p.Jitter); jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay, true
+}
+
+// RetryPolicy bundles the retry settings of WithRetry, WithBackoff and
+// WithRetryJitter.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first; 1 or less
+	// disables retries.
+	MaxAttempts int
+	// Backoff computes the wait between attempts when the server sends no
+	// Retry-After. Nil means ExponentialBackoff(DefaultRetryBaseDelay, 30s).
+	Backoff Backoff
+	// Jitter is the fraction by which waits are randomly shortened, as in
+	// WithRetryJitter, and is clamped to [0, 1] likewise.
+	Jitter float64
+}
+
+// DefaultRetryBaseDelay is the first backoff of a RetryPolicy without one.
+const DefaultRetryBaseDelay = 500 * time.Millisecond
+
+// WithRetryPolicy configures retries from p, replacing earlier WithRetry,
+// WithBackoff and WithRetryJitter options.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.maxAttempts = p.MaxAttempts
+		c.retryBase = DefaultRetryBaseDelay
+		c.backoff = p.Backoff
+		WithRetryJitter(p.Jitter)(c)
+	}
+}
+
+// Backoff returns the wait before retrying a request that failed on the given
+// attempt, starting at 1.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff waits base after the first attempt and doubles the wait
+// after each further one, up to max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// ConstantBackoff waits d between all attempts.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// WithBackoff replaces the exponential backoff of WithRetry. A Retry-After
+// sent by the server still takes precedence.
+func WithBackoff(b Backoff) Option {
+	return func(c *Client) {
+		c.backoff = b
+	}
+}
+
+// WithRetryJitter shortens each backoff by a random amount of up to fraction
+// of it, e.g. 0.5 for waits between half and all of the computed delay, so
+// that clients failing together do not retry in lockstep. A Retry-After sent
+// by the server is never shortened. fraction is clamped to [0, 1].
+func WithRetryJitter(fraction float64) Option {
+	return func(c *Client) {
+		c.retryJitter = clampJitter(fraction)
+	}
+}
+
+func clampJitter(fraction float64) float64 {
+	switch {
+	case fraction < 0 || math.IsNaN(fraction):
+		return 0
+	case fraction > 1:
+		return 1
+	}
+	return fraction
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrRateLimited matches errors for requests rejected with HTTP 429.
+var ErrRateLimited = errors.New("carthooks: rate limited")
+
+// ErrUnavailable matches errors for requests rejected with HTTP 503.
+var ErrUnavailable = errors.New("carthooks: service unavailable")
+
+// RetryAfterError is returned for 429 and 503 responses. It matches
+// ErrRateLimited or ErrUnavailable with errors.Is.
+type RetryAfterError struct {
+	StatusCode int
+	// RetryAfter is the wait requested by the Retry-After header, or zero
+	// if the response had none.
+	RetryAfter time.Duration
+
+	err *APIError
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("carthooks: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("carthooks: status %d, retry after %s", e.StatusCode, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// RetryAfter returns how much longer the API has asked clients to hold off.
+// It is the latest deadline set by the Retry-After header of any 429 or 503
+// response so far, measured from now, and zero once that deadline passed.
+func (c *Client) RetryAfter() time.Duration {
+	s := &c.rateLimit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.retryUntil.Sub(c.clock.Now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// updateRetryAfter records the Retry-After header of a 429 or 503 response,
+// keeping the later of the new and the current deadline.
+func (c *Client) updateRetryAfter(resp *http.Response) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+	now := c.clock.Now()
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok {
+		return
+	}
+	s := &c.rateLimit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := now.Add(d); until.After(s.retryUntil) {
+		s.retryUntil = until
+	}
+}
+
+// waitRetryAfter blocks until the Retry-After deadline passed, so composite
+// operations do not keep issuing requests the API asked to hold back.
+func (c *Client) waitRetryAfter(ctx context.Context) error {
+	d := c.RetryAfter()
+	if d <= 0 {
+		return nil
+	}
+	c.logger.Info("carthooks: waiting for Retry-After", "wait", d, "operation", operationFromContext(ctx))
+	select {
+	case <-c.clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,105 @@
+package carthooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestRetryJitterIsClamped(t *testing.T) {
+	for _, tt := range []struct {
+		jitter   float64
+		min, max time.Duration
+	}{
+		{-1, 100 * time.Millisecond, 100 * time.Millisecond},
+		{0.5, 50 * time.Millisecond, 100 * time.Millisecond},
+		{5, 0, 100 * time.Millisecond},
+	} {
+		calls := 0
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls%2 == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				io.WriteString(w, `{"error":{"key":"ERROR_UNAVAILABLE"}}`)
+				return
+			}
+			io.WriteString(w, `{"data":{"id":3,"fields":{}}}`)
+		}))
+		clock := newFakeClock()
+		c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL), carthooks.WithClock(clock),
+			carthooks.WithRetry(2, 0), carthooks.WithBackoff(carthooks.ConstantBackoff(100*time.Millisecond)),
+			carthooks.WithRetryJitter(tt.jitter))
+		for i := 0; i < 20; i++ {
+			if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 3); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Close()
+		for _, d := range clock.slept {
+			if d < tt.min || d > tt.max {
+				t.Errorf("jitter %v: waited %v, want between %v and %v", tt.jitter, d, tt.min, tt.max)
+			}
+		}
+		if len(clock.slept) != 20 {
+			t.Errorf("jitter %v: %d waits, want 20", tt.jitter, len(clock.slept))
+		}
+	}
+}
+
+// dropFirst returns a handler that closes the connection of the first
+// request without answering, and serves an item to the others. It counts the
+// requests in n.
+func dropFirst(t *testing.T, n *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*n++
+		if *n == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		io.WriteString(w, `{"data":{"id":3,"fields":{}}}`)
+	})
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name      string
+		call      func(c *carthooks.Client) error
+		wantCalls int
+	}{
+		{"GET", func(c *carthooks.Client) error {
+			_, err := c.GetItemByIDContext(ctx, 1, 2, 3)
+			return err
+		}, 2},
+		{"POST without key", func(c *carthooks.Client) error {
+			_, err := c.CreateItemContext(ctx, 1, 2, map[string]interface{}{"title": "a"})
+			return err
+		}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			s := httptest.NewServer(dropFirst(t, &calls))
+			defer s.Close()
+			c := carthooks.NewClient("token", carthooks.WithBaseURL(s.URL), carthooks.WithClock(newFakeClock()),
+				carthooks.WithRetry(3, time.Millisecond))
+
+			err := tt.call(c)
+			if calls != tt.wantCalls {
+				t.Errorf("%d requests, want %d", calls, tt.wantCalls)
+			}
+			if (tt.wantCalls > 1) != (err == nil) {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Revision is one recorded change of an item.
+type Revision struct {
+	ID     int `json:"id"`
+	ItemID int `json:"itemId"`
+	// Action is what happened, e.g. "created", "updated", "archived" or
+	// "restored".
+	Action    string         `json:"action"`
+	Author    RevisionAuthor `json:"author"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Changes   []FieldChange  `json:"changes"`
+}
+
+// RevisionAuthor is the user or API client that made a revision.
+type RevisionAuthor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// FieldChange is the change of one field in a revision.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// GetItemAsOf returns the item as it was at the given time. If the item did
+// not exist then, the error matches ErrNotFound.
+func (c *Client) GetItemAsOf(ctx context.Context, appID, collectionID, itemID int, at time.Time, opts ...ItemOption) (*Item, error) {
+	opts = append(opts[:len(opts):len(opts)], func(o *itemOptions) {
+		o.params.Set("asOf", at.UTC().Format(time.RFC3339Nano))
+	})
+	return c.GetItemByIDContext(ctx, appID, collectionID, itemID, opts...)
+}
+
+// ListItemRevisions returns the revisions of an item, oldest first.
+func (c *Client) ListItemRevisions(ctx context.Context, appID, collectionID, itemID int) ([]Revision, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/revisions",
+		c.baseUrl, appID, collectionID, itemID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []Revision{}
+	if err := rsp.Bind(&revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetItemRevision returns one revision of an item.
+func (c *Client) GetItemRevision(ctx context.Context, appID, collectionID, itemID, revisionID int) (*Revision, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/revisions/%d",
+		c.baseUrl, appID, collectionID, itemID, revisionID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	revision := &Revision{}
+	if err := rsp.Bind(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// ArchiveItem moves an item to the archive. Archived items are left out of
+// listings but can be read with WithIncludeTrashed and brought back with
+// RestoreItem.
+func (c *Client) ArchiveItem(ctx context.Context, appID, collectionID, itemID int) (*Response, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/archive",
+		c.baseUrl, appID, collectionID, itemID)
+	return c.PostContext(ctx, urladdr, nil)
+}
+
+// RestoreItem brings back an archived or deleted item that is still in the
+// trash.
+func (c *Client) RestoreItem(ctx context.Context, appID, collectionID, itemID int) (*Response, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/restore",
+		c.baseUrl, appID, collectionID, itemID)
+	return c.PostContext(ctx, urladdr, nil)
+}
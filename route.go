@@ -0,0 +1,23 @@
+package carthooks
+
+import (
+	"net/url"
+	"strings"
+)
+
+// routeOf returns the path of rawURL with numeric segments replaced by
+// placeholders, e.g. /v1/apps/:id/collections/:id/items. It keeps log and
+// metric labels low-cardinality.
+func routeOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, s := range segments {
+		if s != "" && strings.Trim(s, "0123456789") == "" {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,12 @@
+package carthooks
+
+// FullText restricts the query to items where any field matches term, sent
+// as the _q parameter. It combines with filters: an item must match the term
+// and every filter. An empty term removes the search.
+//
+// No relevance ranking is requested: matches come in the collection's
+// default order, or the order set with OrderBy, as for any other query.
+func (q *Query) FullText(term string) *Query {
+	q.search = term
+	return q
+}
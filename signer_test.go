@@ -0,0 +1,84 @@
+package carthooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestHMACSignerSignature(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		if h := r.Header.Get(carthooks.ContentSHA256Header); h != hash {
+			t.Errorf("%s %s: body hash %q, want %q", r.Method, r.URL, h, hash)
+		}
+		ts := r.Header.Get(carthooks.SignatureTimestampHeader)
+		if _, err := strconv.ParseInt(ts, 10, 64); err != nil {
+			t.Errorf("%s %s: timestamp %q is not a Unix time", r.Method, r.URL, ts)
+		}
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n" + ts + "\n" + hash))
+		if sig := r.Header.Get(carthooks.RequestSignatureHeader); sig != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("%s %s: signature %q does not match", r.Method, r.URL, sig)
+		}
+		if id := r.Header.Get(carthooks.SignatureKeyIDHeader); id != "key-1" {
+			t.Errorf("%s %s: key ID %q, want key-1", r.Method, r.URL, id)
+		}
+		got = append(got, r.Method+" "+r.URL.RequestURI())
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"data":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"id":1,"fields":{}}}`)
+	}))
+	defer srv.Close()
+	c := carthooks.NewClient("", carthooks.WithBaseURL(srv.URL),
+		carthooks.WithSigner(carthooks.HMACSigner{KeyID: "key-1", Secret: "secret"}))
+
+	if _, err := c.CreateItem(1, 2, map[string]interface{}{"title": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Query(1, 2).Filter("title", "$eq", "a b").GetContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got requests %q, want 2", got)
+	}
+}
+
+func TestSignAfterThrottle(t *testing.T) {
+	srv, _ := countingServer(t)
+	clock := newFakeClock()
+	var signedAt []time.Time
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL), carthooks.WithClock(clock),
+		carthooks.WithRateLimiter(1, 1),
+		carthooks.WithSigner(carthooks.SignerFunc(func(*http.Request, []byte) error {
+			signedAt = append(signedAt, clock.Now())
+			return nil
+		})))
+
+	start := clock.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second request waits a second for the limiter and is signed after.
+	want := []time.Time{start, start.Add(time.Second)}
+	if len(signedAt) != 2 || !signedAt[0].Equal(want[0]) || !signedAt[1].Equal(want[1]) {
+		t.Errorf("signed at %v, want %v", signedAt, want)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build go1.21
+
+package carthooks
+
+import "log/slog"
+
+var _ Logger = (*slog.Logger)(nil)
+
+// NewSlogLogger returns a Logger that emits structured records to h. An
+// existing *slog.Logger can also be passed to WithLogger directly.
+func NewSlogLogger(h slog.Handler) Logger {
+	return slog.New(h)
+}
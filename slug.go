@@ -0,0 +1,64 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+type slugCache struct {
+	mu      sync.Mutex
+	entries map[[2]string][2]int
+}
+
+func (s *slugCache) get(key [2]string) ([2]int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, ok := s.entries[key]
+	return ids, ok
+}
+
+func (s *slugCache) set(key [2]string, ids [2]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[[2]string][2]int)
+	}
+	s.entries[key] = ids
+}
+
+// ResolveCollection turns an app and collection slug into their numeric IDs
+// for use with Query, GetItemByID and the other item methods. Numeric strings
+// are taken as IDs as they are. Resolved slugs are cached for the lifetime of
+// the client.
+func (c *Client) ResolveCollection(ctx context.Context, appSlug, collectionSlug string) (appID, collectionID int, err error) {
+	appNum, appErr := strconv.Atoi(appSlug)
+	colNum, colErr := strconv.Atoi(collectionSlug)
+	if appErr == nil && colErr == nil {
+		return appNum, colNum, nil
+	}
+	key := [2]string{appSlug, collectionSlug}
+	if ids, ok := c.slugs.get(key); ok {
+		return ids[0], ids[1], nil
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%s/collections/%s",
+		c.baseUrl, url.PathEscape(appSlug), url.PathEscape(collectionSlug))
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return 0, 0, err
+	}
+	var collection struct {
+		ID    int `json:"id"`
+		AppID int `json:"appId"`
+	}
+	if err := rsp.Bind(&collection); err != nil {
+		return 0, 0, err
+	}
+	if collection.ID == 0 || collection.AppID == 0 {
+		return 0, 0, fmt.Errorf("carthooks: could not resolve collection %s/%s", appSlug, collectionSlug)
+	}
+	c.slugs.set(key, [2]int{collection.AppID, collection.ID})
+	return collection.AppID, collection.ID, nil
+}
@@ -0,0 +1,47 @@
+package carthooks
+
+// SortDirection is the direction of a sort key.
+type SortDirection string
+
+const (
+	Asc  SortDirection = "asc"
+	Desc SortDirection = "desc"
+)
+
+// NullOrder places null values before or after all other values of a sort key.
+type NullOrder string
+
+const (
+	NullsFirst NullOrder = "nullsFirst"
+	NullsLast  NullOrder = "nullsLast"
+)
+
+// Sort appends raw sort keys such as "createdAt:desc", in the API's
+// field[:direction] form, like repeated OrderBy calls.
+func (q *Query) Sort(keys ...string) *Query {
+	q.sort = append(q.sort, keys...)
+	return q
+}
+
+// OrderBy appends a sort key; later calls act as tiebreakers for earlier ones.
+// Keys are sent as a comma-separated sort parameter, e.g.
+// sort=dueDate:desc:nullsLast,id:asc.
+//
+// The null ordering is passed as a third segment of the key. Deployments that
+// do not support it ignore the segment and fall back to the database default,
+// which usually sorts nulls last in ascending and first in descending order.
+// Add a unique tiebreaker such as id for a fully deterministic order.
+func (q *Query) OrderBy(field string, dir SortDirection, nulls ...NullOrder) *Query {
+	key := field
+	if dir != "" {
+		key += ":" + string(dir)
+	}
+	if len(nulls) > 0 && nulls[0] != "" {
+		if dir == "" {
+			key += ":" + string(Asc)
+		}
+		key += ":" + string(nulls[0])
+	}
+	q.sort = append(q.sort, key)
+	return q
+}
@@ -0,0 +1,54 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CollectionStats summarizes a collection's usage.
+type CollectionStats struct {
+	ItemCount    int
+	StorageBytes int64
+	// LastModified is zero if the collection was never modified.
+	LastModified time.Time
+}
+
+// GetCollectionStats returns usage statistics of a collection. A collection
+// the server has no statistics for yields zero values rather than an error.
+func (c *Client) GetCollectionStats(ctx context.Context, appID, collectionID int) (*CollectionStats, error) {
+	key := fmt.Sprintf("stats:%d:%d", appID, collectionID)
+	if v, ok := c.aggregates.get(key, c.clock.Now()); ok {
+		stats := v.(CollectionStats)
+		return &stats, nil
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/stats",
+		c.baseUrl, appID, collectionID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	var raw *struct {
+		ItemCount    int    `json:"itemCount"`
+		StorageBytes int64  `json:"storageBytes"`
+		LastModified string `json:"lastModified"`
+	}
+	if len(rsp.Data) > 0 {
+		if err := rsp.Bind(&raw); err != nil {
+			return nil, err
+		}
+	}
+	stats := CollectionStats{}
+	if raw != nil {
+		stats.ItemCount = raw.ItemCount
+		stats.StorageBytes = raw.StorageBytes
+		if raw.LastModified != "" {
+			stats.LastModified, err = time.Parse(time.RFC3339Nano, raw.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("carthooks: invalid lastModified in collection stats: %w", err)
+			}
+		}
+	}
+	c.aggregates.set(key, stats, c.clock.Now(), c.aggregateTTL)
+	return &stats, nil
+}
@@ -0,0 +1,122 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// errUnexpectedJSON is returned by Client.stream when the server answers with
+// a regular JSON response instead of the requested format.
+var errUnexpectedJSON = errors.New("carthooks: unexpected JSON response")
+
+// ItemStream reads the items of a query one at a time. Create one with
+// Query.Stream and use it like an Iterator, then Close it.
+type ItemStream struct {
+	q    *Query
+	ctx  context.Context
+	body io.ReadCloser
+	dec  *json.Decoder
+	// pages is set when the server does not stream and the items are
+	// paged through instead.
+	pages *Iterator
+	item  Item
+	err   error
+	done  bool
+}
+
+// Stream returns an ItemStream over all items matching the query. It asks the
+// server for the whole result as newline-delimited JSON and decodes one item
+// at a time from the response body, so memory use stays flat however large
+// the result is. If the server does not stream, it falls back to fetching
+// page by page as Iterate does. The query's page and page size only apply in
+// that case.
+//
+// Nothing is fetched until the first call to Next.
+func (q *Query) Stream(ctx context.Context) *ItemStream {
+	return &ItemStream{q: q, ctx: withOperation(ctx, "Stream")}
+}
+
+// Next advances to the next item. It returns false at the end of the result
+// or on an error; see Err.
+func (s *ItemStream) Next() bool {
+	if s.done {
+		return false
+	}
+	if s.dec == nil && s.pages == nil {
+		if err := s.open(); err != nil {
+			return s.fail(err)
+		}
+	}
+	if s.pages != nil {
+		if !s.pages.Next() {
+			return s.fail(s.pages.Err())
+		}
+		s.item = s.pages.Item()
+		return true
+	}
+	item := Item{}
+	if err := s.dec.Decode(&item); err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return s.fail(err)
+	}
+	if err := s.q.client.decodeFields(&item); err != nil {
+		return s.fail(err)
+	}
+	s.item = item
+	return true
+}
+
+func (s *ItemStream) open() error {
+	if s.q.err != nil {
+		return s.q.err
+	}
+	params := s.q.params()
+	params.Del("pagination[page]")
+	params.Del("pagination[pageSize]")
+	params.Del("pagination[withCount]")
+	resp, err := s.q.client.stream(s.ctx, http.MethodGet, s.q.itemsURL(params), nil,
+		http.Header{"Accept": {"application/x-ndjson"}})
+	if errors.Is(err, errUnexpectedJSON) {
+		s.pages = s.q.Iterate(s.ctx)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.body = resp.Body
+	s.dec = json.NewDecoder(resp.Body)
+	return nil
+}
+
+func (s *ItemStream) fail(err error) bool {
+	s.err, s.done = err, true
+	s.Close()
+	return false
+}
+
+// Item returns the item Next advanced to.
+func (s *ItemStream) Item() Item {
+	return s.item
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *ItemStream) Err() error {
+	return s.err
+}
+
+// Close releases the response body. It is safe to call more than once, and
+// unnecessary after Next has returned false.
+func (s *ItemStream) Close() error {
+	s.done = true
+	if s.body == nil {
+		return nil
+	}
+	body := s.body
+	s.body = nil
+	return body.Close()
+}
@@ -0,0 +1,157 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SubmissionTokenOptions are the typed options for submission and update
+// tokens used by embedded forms.
+type SubmissionTokenOptions struct {
+	// Expiry is how long the token stays valid. Required; sent in whole
+	// seconds as expiresIn.
+	Expiry time.Duration
+	// AllowedFields restricts the form to these fields. Empty allows all.
+	AllowedFields []string
+	// RedirectURL is where the form redirects after a successful submit.
+	// It must be an absolute http(s) URL.
+	RedirectURL string
+	// Prefill holds initial field values shown in the form, encoded like
+	// the data of CreateItem.
+	Prefill map[string]interface{}
+}
+
+// Validate checks the options locally before they are sent.
+func (o SubmissionTokenOptions) Validate() error {
+	if o.Expiry < time.Second {
+		return errors.New("carthooks: submission token expiry must be at least one second")
+	}
+	for _, field := range o.AllowedFields {
+		if field == "" {
+			return errors.New("carthooks: submission token allowed fields must not be empty")
+		}
+	}
+	if o.RedirectURL != "" {
+		u, err := url.Parse(o.RedirectURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("carthooks: invalid submission token redirect URL %q", o.RedirectURL)
+		}
+	}
+	return nil
+}
+
+// submissionBody validates opts and encodes them as a request body.
+func (c *Client) submissionBody(o SubmissionTokenOptions) (map[string]interface{}, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"expiresIn": int(o.Expiry / time.Second),
+	}
+	if len(o.AllowedFields) > 0 {
+		body["allowedFields"] = o.AllowedFields
+	}
+	if o.RedirectURL != "" {
+		body["redirectUrl"] = o.RedirectURL
+	}
+	if len(o.Prefill) > 0 {
+		prefill, err := c.encodeFields(o.Prefill)
+		if err != nil {
+			return nil, err
+		}
+		body["prefill"] = prefill
+	}
+	return body, nil
+}
+
+// SubmissionToken is a token for an embedded form.
+type SubmissionToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// URL is the hosted form for the token, as returned by the server or
+	// else built with the base set by WithFormBaseURL. It is empty if
+	// neither is available.
+	URL string `json:"url"`
+}
+
+// WithFormBaseURL sets the address of the hosted forms, used to build the
+// URL of submission tokens the server returns without one.
+func WithFormBaseURL(u string) Option {
+	return func(c *Client) {
+		c.formBaseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// SubmissionFormURL returns the hosted form URL for token. It needs
+// WithFormBaseURL.
+func (c *Client) SubmissionFormURL(token string) (string, error) {
+	if c.formBaseURL == "" {
+		return "", errors.New("carthooks: no form base URL set; see WithFormBaseURL")
+	}
+	if token == "" {
+		return "", errors.New("carthooks: empty submission token")
+	}
+	return c.formBaseURL + "/" + url.PathEscape(token), nil
+}
+
+// CreateSubmissionToken requests a token for a form that creates an item in
+// the collection.
+func (c *Client) CreateSubmissionToken(ctx context.Context, appID, collectionID int, opts SubmissionTokenOptions) (*SubmissionToken, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/submission-token",
+		c.baseUrl, appID, collectionID)
+	return c.submissionToken(ctx, urladdr, opts)
+}
+
+// CreateUpdateToken requests a token for a form that updates the item.
+func (c *Client) CreateUpdateToken(ctx context.Context, appID, collectionID, itemID int, opts SubmissionTokenOptions) (*SubmissionToken, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/update-token",
+		c.baseUrl, appID, collectionID, itemID)
+	return c.submissionToken(ctx, urladdr, opts)
+}
+
+func (c *Client) submissionToken(ctx context.Context, urladdr string, opts SubmissionTokenOptions) (*SubmissionToken, error) {
+	body, err := c.submissionBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := c.PostContext(ctx, urladdr, body)
+	if err != nil {
+		return nil, err
+	}
+	token := &SubmissionToken{}
+	if err := rsp.Bind(token); err != nil {
+		return nil, err
+	}
+	if token.URL == "" && c.formBaseURL != "" && token.Token != "" {
+		token.URL, _ = c.SubmissionFormURL(token.Token)
+	}
+	return token, nil
+}
+
+// GetSubmissionTokenWithOptions is GetSubmissionToken with typed, locally
+// validated options.
+func (c *Client) GetSubmissionTokenWithOptions(ctx context.Context, appID, collectionID int, opts SubmissionTokenOptions) (*Response, error) {
+	body, err := c.submissionBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/submission-token",
+		c.baseUrl, appID, collectionID)
+	return c.PostContext(ctx, urladdr, body)
+}
+
+// UpdateSubmissionTokenWithOptions is UpdateSubmissionToken with typed,
+// locally validated options.
+func (c *Client) UpdateSubmissionTokenWithOptions(ctx context.Context, appID, collectionID, itemID int, opts SubmissionTokenOptions) (*Response, error) {
+	body, err := c.submissionBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/items/%d/update-token",
+		c.baseUrl, appID, collectionID, itemID)
+	return c.PostContext(ctx, urladdr, body)
+}
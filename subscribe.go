@@ -0,0 +1,275 @@
+package carthooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeType is the kind of change a ChangeEvent reports.
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "item.created"
+	ChangeUpdated ChangeType = "item.updated"
+	ChangeDeleted ChangeType = "item.deleted"
+)
+
+// ChangeEvent is a change to an item of a subscribed collection.
+type ChangeEvent struct {
+	// ID identifies the event in the stream. Passing it to WithResumeFrom
+	// resumes a later subscription after this event.
+	ID     string
+	Type   ChangeType
+	ItemID int
+	// Item is the item after the change. For deletions it may be nil.
+	Item *Item
+}
+
+// defaultReconnectDelay is the first wait before reconnecting a
+// subscription.
+const defaultReconnectDelay = time.Second
+
+// SubscribeOption customizes Subscribe.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	lastEventID string
+	delay       time.Duration
+	buffer      int
+}
+
+// WithResumeFrom resumes a subscription after the event with the given ID,
+// so changes made while the subscriber was away are delivered too, as far as
+// the server still has them.
+func WithResumeFrom(eventID string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.lastEventID = eventID
+	}
+}
+
+// WithReconnectDelay sets the first wait before reconnecting after the
+// stream breaks. It doubles on each failed attempt, up to 30 seconds; a
+// retry interval sent by the server takes precedence.
+func WithReconnectDelay(d time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.delay = d
+	}
+}
+
+// WithEventBuffer sets the capacity of the event channel.
+func WithEventBuffer(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.buffer = n
+	}
+}
+
+// Subscription delivers the change events of a collection. Read them from C
+// until it is closed, then check Err.
+type Subscription struct {
+	C <-chan ChangeEvent
+
+	mu          sync.Mutex
+	err         error
+	lastEventID string
+}
+
+// Err returns why the subscription ended: nil after the context was
+// cancelled, or the error that made reconnecting pointless, such as a 404
+// response, or a 401 that persists with a fresh token.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// LastEventID returns the ID of the last event delivered, for WithResumeFrom.
+func (s *Subscription) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventID
+}
+
+// Subscribe streams changes to the items of a collection from the API's
+// server-sent events endpoint. The first connection is made before Subscribe
+// returns, and its failure is returned. Afterwards, when the stream breaks,
+// the subscription reconnects and resumes after the last event received,
+// until ctx is done, at which point C is closed.
+//
+// Events are delivered in order. A slow reader holds up the stream; the
+// server may then drop the connection, which is resumed as usual. A client
+// timeout set with WithTimeout also ends each connection after that time.
+func (c *Client) Subscribe(ctx context.Context, appID, collectionID int, opts ...SubscribeOption) (*Subscription, error) {
+	ctx = withOperation(ctx, "Subscribe")
+	o := subscribeOptions{delay: defaultReconnectDelay}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.delay <= 0 {
+		o.delay = defaultReconnectDelay
+	}
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/collections/%d/events", c.baseUrl, appID, collectionID)
+	events := make(chan ChangeEvent, o.buffer)
+	sub := &Subscription{C: events, lastEventID: o.lastEventID}
+
+	body, err := c.openEvents(ctx, urladdr, o.lastEventID)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(events)
+		delay := o.delay
+		for {
+			retry, err := c.readEvents(ctx, body, sub, events)
+			body.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			if retry > 0 {
+				delay = retry
+			}
+			c.logger.Warn("carthooks: event stream interrupted", "route", routeOf(urladdr), "error", err)
+			for {
+				select {
+				case <-c.clock.After(delay):
+				case <-ctx.Done():
+					return
+				}
+				body, err = c.openEvents(ctx, urladdr, sub.LastEventID())
+				if err == nil {
+					delay = o.delay
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				if permanentStreamError(err) {
+					sub.mu.Lock()
+					sub.err = err
+					sub.mu.Unlock()
+					return
+				}
+				c.logger.Warn("carthooks: reconnecting event stream failed", "route", routeOf(urladdr), "error", err)
+				if delay *= 2; delay > maxRetryDelay {
+					delay = maxRetryDelay
+				}
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// openEvents connects to the event stream. Like other requests, a
+// connection rejected with 401 is made once more with a fresh token from the
+// token provider, so a subscription outlives the token it started with.
+func (c *Client) openEvents(ctx context.Context, urladdr, lastEventID string) (io.ReadCloser, error) {
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if lastEventID != "" {
+		header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := c.stream(ctx, http.MethodGet, urladdr, nil, header)
+	// A token given for the call is not the client's to refresh.
+	if o := requestOptionsFrom(ctx); err != nil && (o == nil || o.token == "") && c.refreshOnUnauthorized(err) {
+		resp, err = c.stream(ctx, http.MethodGet, urladdr, nil, header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// permanentStreamError reports whether err is a rejection that reconnecting
+// will not change, such as a missing collection or a revoked token.
+func permanentStreamError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests
+}
+
+// readEvents parses a server-sent events stream into events until it ends,
+// returning the retry interval last sent by the server, if any.
+func (c *Client) readEvents(ctx context.Context, body io.Reader, sub *Subscription, events chan<- ChangeEvent) (retry time.Duration, err error) {
+	r := bufio.NewReader(body)
+	var id, name string
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return retry, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if len(data) > 0 {
+				e, err := c.changeEvent(id, name, strings.Join(data, "\n"))
+				if err != nil {
+					c.logger.Warn("carthooks: skipping malformed event", "id", id, "error", err)
+				} else {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return retry, ctx.Err()
+					}
+				}
+				if id != "" {
+					sub.mu.Lock()
+					sub.lastEventID = id
+					sub.mu.Unlock()
+				}
+			}
+			name, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			name = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// changeEvent decodes the data of one event.
+func (c *Client) changeEvent(id, name, data string) (ChangeEvent, error) {
+	var payload struct {
+		Type   ChangeType `json:"type"`
+		ItemID int        `json:"itemId"`
+		Item   *Item      `json:"item"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return ChangeEvent{}, err
+	}
+	e := ChangeEvent{ID: id, Type: payload.Type, ItemID: payload.ItemID, Item: payload.Item}
+	if e.Type == "" {
+		e.Type = ChangeType(name)
+	}
+	if e.Item != nil {
+		if err := c.decodeFields(e.Item); err != nil {
+			return ChangeEvent{}, err
+		}
+		if e.ItemID == 0 {
+			e.ItemID = e.Item.ID
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,76 @@
+package carthooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestSubscribeRefreshesTokenOnReconnect(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		seen  []string
+		valid = "Bearer token-1"
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		n, accepted := len(seen), r.Header.Get("Authorization") == valid
+		// The first token expires once the first stream has ended.
+		valid = "Bearer token-2"
+		mu.Unlock()
+		if !accepted {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"key":"ERROR_UNAUTHORIZED"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "id: %d\ndata: {\"type\":\"item.created\",\"itemId\":%d}\n\n", n, n)
+		w.(http.Flusher).Flush()
+		if n > 1 {
+			<-r.Context().Done()
+		}
+	}))
+	defer s.Close()
+	calls := 0
+	c := carthooks.NewClient("", carthooks.WithBaseURL(s.URL), carthooks.WithClock(newFakeClock()),
+		carthooks.WithTokenProvider(func(context.Context) (string, error) {
+			calls++
+			return fmt.Sprintf("token-%d", calls), nil
+		}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := c.Subscribe(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for len(ids) < 2 {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				t.Fatalf("subscription ended after %v: %v", ids, sub.Err())
+			}
+			ids = append(ids, e.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out after events %v", ids)
+		}
+	}
+	cancel()
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}; fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("connections sent %q, want %q", seen, want)
+	}
+	if fmt.Sprint(ids) != "[1 3]" {
+		t.Errorf("event IDs = %v, want [1 3]", ids)
+	}
+}
@@ -0,0 +1,33 @@
+package carthooks
+
+import (
+	"context"
+	"time"
+)
+
+// ChangedSince returns the items of a collection updated at or after since,
+// oldest change first, fetching all pages. checkpoint is the latest
+// UpdatedAt among them, or since if there are none; pass it as since on the
+// next run.
+//
+// Because the bound is inclusive, items updated exactly at the checkpoint
+// are returned again on the next run, so consumers should apply changes
+// idempotently. On error, the items fetched so far are discarded.
+func (c *Client) ChangedSince(ctx context.Context, appID, collectionID int, since time.Time) (items []Item, checkpoint time.Time, err error) {
+	ctx = withOperation(ctx, "ChangedSince")
+	items, err = c.Query(appID, collectionID).
+		Filter("updatedAt", "$gte", since.UTC().Format(time.RFC3339Nano)).
+		OrderBy("updatedAt", Asc).
+		OrderBy("id", Asc).
+		GetAll(ctx)
+	if err != nil {
+		return nil, since, err
+	}
+	checkpoint = since
+	for _, item := range items {
+		if item.UpdatedAt.After(checkpoint) {
+			checkpoint = item.UpdatedAt
+		}
+	}
+	return items, checkpoint, nil
+}
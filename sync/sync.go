@@ -0,0 +1,225 @@
+// Package sync copies the items of one Carthooks collection into
+// another, possibly in another app or environment, e.g. to promote data from
+// staging to production.
+//
+// Items are matched across the two collections by a key field, since item
+// IDs differ between them. Runs are incremental: each returns a cursor to
+// pass as Since to the next one.
+//
+//	res, err := sync.Run(ctx, sync.Config{
+//		Source:   sync.Endpoint{Client: staging, AppID: 1, CollectionID: 2},
+//		Target:   sync.Endpoint{Client: production, AppID: 7, CollectionID: 9},
+//		KeyField: "sku",
+//		Since:    lastCursor,
+//	})
+//	...
+//	lastCursor = res.Cursor
+//
+// Programs that also use the standard library's sync package import this one
+// under another name.
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// Endpoint is one side of a replication.
+type Endpoint struct {
+	Client       *carthooks.Client
+	AppID        int
+	CollectionID int
+}
+
+// ConflictPolicy decides what happens to a target item that already exists.
+type ConflictPolicy int
+
+const (
+	// SourceWins overwrites the target item with the source item.
+	SourceWins ConflictPolicy = iota
+	// NewerWins overwrites the target item only if the source item was
+	// updated after it.
+	NewerWins
+	// SkipExisting leaves existing target items alone and only creates
+	// missing ones.
+	SkipExisting
+)
+
+// Config describes a replication.
+type Config struct {
+	Source Endpoint
+	Target Endpoint
+
+	// KeyField is the source field identifying an item in both
+	// collections. Required.
+	KeyField string
+
+	// FieldMap maps source field names to target field names. Only mapped
+	// fields are copied; a nil FieldMap copies every field as it is. System
+	// fields such as updatedAt are never copied.
+	FieldMap map[string]string
+
+	// Since limits the run to source items updated at or after it. A zero
+	// Since replicates everything.
+	Since time.Time
+
+	Conflict ConflictPolicy
+
+	// DryRun reports what would be written without writing anything.
+	DryRun bool
+
+	// Progress, if set, is called after each source item.
+	Progress func(Progress)
+}
+
+// Progress counts the source items handled so far.
+type Progress struct {
+	Processed int
+	Created   int
+	Updated   int
+	// Skipped counts items left alone because the target was up to date
+	// or the conflict policy said so.
+	Skipped int
+	Failed  int
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Progress
+	// Errors holds the failures by source item ID.
+	Errors map[int]error
+	// Cursor is the Since of the next run: the latest update time of the
+	// source items replicated before the first failure.
+	Cursor time.Time
+}
+
+// Run replicates the source items updated since cfg.Since, oldest change
+// first. Failures of single items are collected in Result.Errors and do not
+// stop the run; the returned error reports a failure to read the source or
+// an invalid config.
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	if cfg.Source.Client == nil || cfg.Target.Client == nil {
+		return nil, errors.New("sync: source and target need a client")
+	}
+	if cfg.KeyField == "" {
+		return nil, errors.New("sync: no key field")
+	}
+	if _, ok := cfg.FieldMap[cfg.KeyField]; cfg.FieldMap != nil && !ok {
+		return nil, fmt.Errorf("sync: key field %q is not mapped", cfg.KeyField)
+	}
+	res := &Result{Errors: map[int]error{}, Cursor: cfg.Since}
+	q := cfg.Source.Client.Query(cfg.Source.AppID, cfg.Source.CollectionID).
+		OrderBy("updatedAt", carthooks.Asc).
+		OrderBy("id", carthooks.Asc)
+	if !cfg.Since.IsZero() {
+		q.Where("updatedAt", carthooks.OpGte, cfg.Since)
+	}
+	err := q.Each(ctx, func(item carthooks.Item) error {
+		res.Processed++
+		switch outcome, err := replicateItem(ctx, cfg, item); {
+		case err != nil:
+			res.Failed++
+			res.Errors[item.ID] = err
+		case outcome == created:
+			res.Created++
+		case outcome == updated:
+			res.Updated++
+		default:
+			res.Skipped++
+		}
+		if len(res.Errors) == 0 && item.UpdatedAt.After(res.Cursor) {
+			res.Cursor = item.UpdatedAt
+		}
+		if cfg.Progress != nil {
+			cfg.Progress(res.Progress)
+		}
+		return ctx.Err()
+	})
+	return res, err
+}
+
+type outcome int
+
+const (
+	skipped outcome = iota
+	created
+	updated
+)
+
+func replicateItem(ctx context.Context, cfg Config, item carthooks.Item) (outcome, error) {
+	fields := mapFields(cfg.FieldMap, item.Fields)
+	keyField := cfg.KeyField
+	if cfg.FieldMap != nil {
+		keyField = cfg.FieldMap[cfg.KeyField]
+	}
+	key, ok := fields[keyField]
+	if !ok || key == nil {
+		return skipped, fmt.Errorf("sync: item %d has no key field %q", item.ID, cfg.KeyField)
+	}
+	t := cfg.Target
+	matches, err := t.Client.Query(t.AppID, t.CollectionID).
+		Where(keyField, carthooks.OpEq, key).
+		Limit(2).
+		WithoutCount().
+		GetContext(ctx)
+	if err != nil {
+		return skipped, err
+	}
+	switch len(matches) {
+	case 0:
+		if !cfg.DryRun {
+			if _, err := t.Client.CreateItemContext(ctx, t.AppID, t.CollectionID, fields); err != nil {
+				return skipped, err
+			}
+		}
+		return created, nil
+	case 1:
+	default:
+		return skipped, fmt.Errorf("%w: %s = %v", carthooks.ErrAmbiguousMatch, keyField, key)
+	}
+	target := matches[0]
+	switch {
+	case cfg.Conflict == SkipExisting,
+		cfg.Conflict == NewerWins && !item.UpdatedAt.After(target.UpdatedAt),
+		upToDate(target.Fields, fields):
+		return skipped, nil
+	}
+	if !cfg.DryRun {
+		if _, err := t.Client.PatchItem(ctx, t.AppID, t.CollectionID, target.ID, fields); err != nil {
+			return skipped, err
+		}
+	}
+	return updated, nil
+}
+
+// mapFields renames the fields of a source item for the target, dropping
+// system fields and unmapped ones. A nil mapping copies all others.
+func mapFields(mapping map[string]string, fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		if carthooks.IsSystemField(field) {
+			continue
+		}
+		if mapping == nil {
+			out[field] = value
+		} else if name, ok := mapping[field]; ok {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// upToDate reports whether the target already holds the given fields.
+func upToDate(target, fields map[string]interface{}) bool {
+	for field, value := range fields {
+		if !reflect.DeepEqual(target[field], value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package sync_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+	"github.com/carthooks/carthooks-sdk-golang/sync"
+)
+
+func TestRunSkipsSystemFields(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	c := s.Client()
+	s.AddItem(1, 2, map[string]interface{}{"sku": "A-1", "stock": 5, "createdAt": "2026-01-01T00:00:00Z", "updatedAt": "2026-01-02T00:00:00Z"})
+	s.AddItem(1, 2, map[string]interface{}{"sku": "B-2", "stock": 7, "createdAt": "2026-01-01T00:00:00Z", "updatedAt": "2026-01-03T00:00:00Z"})
+	// Up to date apart from its own timestamps.
+	s.AddItem(7, 9, map[string]interface{}{"sku": "A-1", "stock": 5, "createdAt": "2026-02-01T00:00:00Z", "updatedAt": "2026-02-01T00:00:00Z"})
+	// Outdated, with a field of its own.
+	outdated := s.AddItem(7, 9, map[string]interface{}{"sku": "B-2", "stock": 1, "note": "keep me"})
+
+	res, err := sync.Run(context.Background(), sync.Config{
+		Source:   sync.Endpoint{Client: c, AppID: 1, CollectionID: 2},
+		Target:   sync.Endpoint{Client: c, AppID: 7, CollectionID: 9},
+		KeyField: "sku",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped != 1 || res.Updated != 1 || res.Failed != 0 {
+		t.Errorf("got %+v, want 1 skipped and 1 updated", res.Progress)
+	}
+	got, _ := s.Item(7, 9, outdated.ID)
+	if want := map[string]interface{}{"sku": "B-2", "stock": float64(7), "note": "keep me"}; !reflect.DeepEqual(got.Fields, want) {
+		t.Errorf("got %v, want %v", got.Fields, want)
+	}
+	for _, r := range s.Requests() {
+		if r.Method == "PUT" {
+			t.Errorf("Run sent PUT %s", r.Path)
+		}
+	}
+}
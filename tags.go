@@ -0,0 +1,72 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+)
+
+// AddTag adds tag to the multi-select field of each item, keeping the tags
+// already set. Items that already carry the tag are left alone.
+//
+// Each item is read, modified and written back conditionally on its ETag; if
+// it changed in between, the cycle is retried. Per-item failures are reported
+// as ItemErrors.
+func (c *Client) AddTag(ctx context.Context, appID, collectionID int, itemIDs []int, field, tag string) error {
+	ctx = withOperation(ctx, "AddTag")
+	return c.modifyTags(ctx, appID, collectionID, itemIDs, field, func(tags []interface{}) ([]interface{}, bool) {
+		if indexOfTag(tags, tag) >= 0 {
+			return tags, false
+		}
+		return append(tags, tag), true
+	})
+}
+
+// RemoveTag removes tag from the multi-select field of each item, keeping the
+// other tags. It retries and reports failures like AddTag.
+func (c *Client) RemoveTag(ctx context.Context, appID, collectionID int, itemIDs []int, field, tag string) error {
+	ctx = withOperation(ctx, "RemoveTag")
+	return c.modifyTags(ctx, appID, collectionID, itemIDs, field, func(tags []interface{}) ([]interface{}, bool) {
+		i := indexOfTag(tags, tag)
+		if i < 0 {
+			return tags, false
+		}
+		return append(tags[:i:i], tags[i+1:]...), true
+	})
+}
+
+func (c *Client) modifyTags(ctx context.Context, appID, collectionID int, itemIDs []int, field string, change func([]interface{}) ([]interface{}, bool)) error {
+	refs := make([]ItemRef, len(itemIDs))
+	for i, id := range itemIDs {
+		refs[i] = ItemRef{AppID: appID, CollectionID: collectionID, ItemID: id}
+	}
+	errs := c.forEachRef(ctx, refs, DefaultConcurrency, func(ref ItemRef) error {
+		return c.modifyItem(ctx, ref, func(item *Item) (map[string]interface{}, error) {
+			var tags []interface{}
+			switch v := item.Fields[field].(type) {
+			case nil:
+			case []interface{}:
+				tags = v
+			default:
+				return nil, fmt.Errorf("carthooks: field %q is not a multi-select field", field)
+			}
+			tags, changed := change(tags)
+			if !changed {
+				return nil, nil
+			}
+			return map[string]interface{}{field: tags}, nil
+		}, WithFields(field))
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func indexOfTag(tags []interface{}, tag string) int {
+	for i, t := range tags {
+		if fmt.Sprint(t) == tag {
+			return i
+		}
+	}
+	return -1
+}
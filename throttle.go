@@ -0,0 +1,99 @@
+package carthooks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimiter makes the client pace its own requests to rps requests per
+// second, allowing bursts of up to burst requests. Every outbound request
+// waits for a token, or fails with the context error if ctx is done first.
+// The time spent waiting is reported as RequestInfo.ThrottleWait. A
+// non-positive rps disables the limiter.
+//
+// The limiter also follows the budget the API reports in its
+// X-RateLimit-Remaining and X-RateLimit-Reset headers: once the budget is
+// used up, requests wait for the reset instead of running into 429
+// responses. See Client.RateLimitStatus.
+func WithRateLimiter(rps float64, burst int) Option {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &tokenBucket{rate: rps, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// tokenBucket is a token-bucket rate limiter driven by the client's Clock.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. The balance may go negative; later callers queue behind.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	return b.reserveN(now, 1)
+}
+
+// reserveN takes n tokens at once, as reserve does.
+func (b *tokenBucket) reserveN(now time.Time, n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// throttle waits for the rate limiter, if any, and returns how long it
+// waited.
+func (c *Client) throttle(ctx context.Context) (time.Duration, error) {
+	if c.limiter == nil {
+		return 0, nil
+	}
+	now := c.clock.Now()
+	wait := c.limiter.reserve(now)
+	if budgetWait := c.rateLimit.reserveBudget(now); budgetWait > wait {
+		wait = budgetWait
+	}
+	if wait <= 0 {
+		return 0, nil
+	}
+	c.logger.Info("carthooks: throttling request", "wait", wait, "operation", operationFromContext(ctx))
+	select {
+	case <-c.clock.After(wait):
+		return wait, nil
+	case <-ctx.Done():
+		c.limiter.cancel()
+		return 0, ctx.Err()
+	}
+}
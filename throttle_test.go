@@ -0,0 +1,49 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	srv, hits := countingServer(t)
+	clock := newFakeClock()
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL), carthooks.WithClock(clock),
+		carthooks.WithRateLimiter(10, 2))
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hits("/v1/apps/1/collections/2/items/3"); n != 5 {
+		t.Fatalf("got %d requests, want 5", n)
+	}
+	// The burst goes through at once; the rest are spaced 1/rps apart.
+	want := []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}
+	if !reflect.DeepEqual(clock.slept, want) {
+		t.Errorf("waited %v, want %v", clock.slept, want)
+	}
+}
+
+func TestRateLimiterStopsWaitingWhenContextDone(t *testing.T) {
+	srv, hits := countingServer(t)
+	c := carthooks.NewClient("token", carthooks.WithBaseURL(srv.URL), carthooks.WithRateLimiter(0.01, 1))
+
+	if _, err := c.GetItemByIDContext(context.Background(), 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if n := hits("/v1/apps/1/collections/2/items/3"); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,168 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how long before its expiry a token is refreshed, so
+// that it does not expire in flight.
+const tokenExpiryDelta = 10 * time.Second
+
+// Token is an access token obtained from a TokenSource.
+type Token struct {
+	AccessToken string
+	// Expiry is when the token stops being valid. A zero Expiry means the
+	// token is used until the API rejects it.
+	Expiry time.Time
+}
+
+// TokenSource supplies access tokens, for example from an OAuth2 flow. Use
+// OAuth2TokenSource to adapt a golang.org/x/oauth2 TokenSource.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// OAuth2TokenSource adapts a golang.org/x/oauth2 TokenSource, keeping the
+// expiry of its tokens, without this package depending on oauth2:
+//
+//	carthooks.WithTokenSource(carthooks.OAuth2TokenSource[*oauth2.Token](ts))
+//
+// T may be any struct, or pointer to one, with an AccessToken string field
+// and optionally an Expiry time.Time field, as oauth2.Token has.
+func OAuth2TokenSource[T any](ts interface{ Token() (T, error) }) TokenSource {
+	return oauth2Source[T]{ts}
+}
+
+type oauth2Source[T any] struct {
+	ts interface{ Token() (T, error) }
+}
+
+func (s oauth2Source[T]) Token(context.Context) (*Token, error) {
+	t, err := s.ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(t)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, errors.New("carthooks: token source returned no token")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("carthooks: %T is not a token", t)
+	}
+	access := v.FieldByName("AccessToken")
+	if !access.IsValid() || access.Kind() != reflect.String {
+		return nil, fmt.Errorf("carthooks: %T has no AccessToken string field", t)
+	}
+	token := &Token{AccessToken: access.String()}
+	if expiry := v.FieldByName("Expiry"); expiry.IsValid() && expiry.CanInterface() {
+		token.Expiry, _ = expiry.Interface().(time.Time)
+	}
+	return token, nil
+}
+
+// WithTokenProvider makes the client obtain its access token from fn instead
+// of the static token passed to NewClient. The token is cached until a
+// request is rejected with 401 Unauthorized or InvalidateToken is called;
+// the rejected request is then retried once with a fresh token. fn is not
+// called concurrently.
+func WithTokenProvider(fn func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.tokens.provider = func(ctx context.Context) (*Token, error) {
+			token, err := fn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &Token{AccessToken: token}, nil
+		}
+	}
+}
+
+// WithTokenSource is like WithTokenProvider, but also fetches a new token
+// shortly before the cached one expires.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		c.tokens.provider = ts.Token
+	}
+}
+
+// tokenSource caches the token of a provider.
+type tokenSource struct {
+	mu       sync.Mutex
+	provider func(ctx context.Context) (*Token, error)
+	token    string
+	expiry   time.Time
+	valid    bool
+}
+
+// InvalidateToken drops the cached access token, so the next request asks
+// the token provider for a new one. It has no effect on a static token.
+func (c *Client) InvalidateToken() {
+	s := &c.tokens
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = false
+}
+
+// AccessToken returns the access token the client sends, fetching one from
+// its token provider if none is cached.
+func (c *Client) AccessToken(ctx context.Context) (string, error) {
+	return c.accessTokenFor(ctx)
+}
+
+// accessTokenFor returns the token to send, from the provider if one is set.
+func (c *Client) accessTokenFor(ctx context.Context) (string, error) {
+	s := &c.tokens
+	if s.provider == nil {
+		return c.accessToken, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.valid && (s.expiry.IsZero() || c.clock.Now().Add(tokenExpiryDelta).Before(s.expiry)) {
+		return s.token, nil
+	}
+	token, err := s.provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == nil {
+		return "", errors.New("carthooks: token source returned no token")
+	}
+	s.token, s.expiry, s.valid = token.AccessToken, token.Expiry, true
+	return s.token, nil
+}
+
+// authorize sets the bearer token on req, if there is one.
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if o := requestOptionsFrom(ctx); o != nil && o.token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+		return nil
+	}
+	token, err := c.accessTokenFor(ctx)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// refreshOnUnauthorized reports whether a request that failed with err
+// should be retried with a new token, invalidating the cached one if so.
+func (c *Client) refreshOnUnauthorized(err error) bool {
+	var apiErr *APIError
+	if c.tokens.provider == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	c.InvalidateToken()
+	return true
+}
@@ -0,0 +1,158 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// oauth2Token mirrors golang.org/x/oauth2.Token.
+type oauth2Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// oauth2Source mirrors an oauth2.TokenSource, minting a token per call.
+type oauth2Source struct {
+	calls  int
+	expiry time.Time
+}
+
+func (s *oauth2Source) Token() (*oauth2Token, error) {
+	s.calls++
+	return &oauth2Token{AccessToken: fmt.Sprintf("token-%d", s.calls), TokenType: "Bearer", Expiry: s.expiry}, nil
+}
+
+func TestOAuth2TokenSourceKeepsExpiry(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"data":{"id":1,"fields":{}}}`)
+	}))
+	defer srv.Close()
+	clock := newFakeClock()
+	src := &oauth2Source{expiry: clock.Now().Add(time.Hour)}
+	c := carthooks.NewClient("", carthooks.WithBaseURL(srv.URL), carthooks.WithClock(clock),
+		carthooks.WithTokenSource(carthooks.OAuth2TokenSource[*oauth2Token](src)))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetItemByIDContext(ctx, 1, 2, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Past the expiry the client asks the source again.
+	clock.Advance(time.Hour)
+	src.expiry = clock.Now().Add(time.Hour)
+	if _, err := c.GetItemByIDContext(ctx, 1, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("sent %q, want %q", seen, want)
+	}
+}
+
+func TestClientCredentialsMalformedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token":"abc","expires_in":"soon"}`)
+	}))
+	defer srv.Close()
+	c := carthooks.NewClient("", carthooks.WithBaseURL(srv.URL), carthooks.WithClientCredentials("id", "secret"))
+	if _, err := c.AccessToken(context.Background()); err == nil {
+		t.Error("no error for a malformed token response")
+	}
+}
+
+func TestTokenProviderRefreshesOnUnauthorized(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		accepted string
+		wantErr  bool
+	}{
+		{"fresh token accepted", "Bearer token-2", false},
+		{"fresh token rejected", "", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.Header.Get("Authorization") != tt.accepted {
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprint(w, `{"error":{"key":"ERROR_UNAUTHORIZED"}}`)
+					return
+				}
+				fmt.Fprint(w, `{"data":{"id":1,"fields":{}}}`)
+			}))
+			defer srv.Close()
+			calls := 0
+			c := carthooks.NewClient("", carthooks.WithBaseURL(srv.URL),
+				carthooks.WithTokenProvider(func(context.Context) (string, error) {
+					calls++
+					return fmt.Sprintf("token-%d", calls), nil
+				}))
+
+			_, err := c.GetItemByIDContext(context.Background(), 1, 2, 1)
+			var apiErr *carthooks.APIError
+			switch {
+			case tt.wantErr && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized):
+				t.Errorf("err = %v, want the second 401", err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("err = %v, want success with the fresh token", err)
+			}
+			if calls != 2 || requests != 2 {
+				t.Errorf("provider called %d times for %d requests, want 2 and 2", calls, requests)
+			}
+		})
+	}
+}
+
+// tokenSource mints a token per call, each valid for an hour.
+type tokenSource struct {
+	clock *fakeClock
+	calls int
+}
+
+func (s *tokenSource) Token(context.Context) (*carthooks.Token, error) {
+	s.calls++
+	return &carthooks.Token{AccessToken: fmt.Sprintf("token-%d", s.calls), Expiry: s.clock.Now().Add(time.Hour)}, nil
+}
+
+func TestTokenSourceRefreshesBeforeExpiry(t *testing.T) {
+	clock := newFakeClock()
+	src := &tokenSource{clock: clock}
+	c := carthooks.NewClient("", carthooks.WithClock(clock), carthooks.WithTokenSource(src))
+	ctx := context.Background()
+	token := func() string {
+		t.Helper()
+		token, err := c.AccessToken(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	if got := token(); got != "token-1" {
+		t.Fatalf("got %q, want token-1", got)
+	}
+	clock.Advance(50 * time.Minute)
+	if got := token(); got != "token-1" {
+		t.Errorf("got %q ten minutes before expiry, want the cached token-1", got)
+	}
+	// Within seconds of the expiry the token is replaced before it is sent.
+	clock.Advance(10*time.Minute - 5*time.Second)
+	if got := token(); got != "token-2" {
+		t.Errorf("got %q five seconds before expiry, want a fresh token-2", got)
+	}
+	if src.calls != 2 {
+		t.Errorf("source called %d times, want 2", src.calls)
+	}
+}
@@ -0,0 +1,79 @@
+package carthooks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type operationKey struct{}
+type traceCollectorKey struct{}
+
+// withOperation tags requests made with ctx as part of the named composite
+// operation, reported as RequestInfo.Operation. An operation already set by
+// an outer call is kept.
+func withOperation(ctx context.Context, name string) context.Context {
+	if _, ok := ctx.Value(operationKey{}).(string); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+func operationFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// TraceIDs collects the trace IDs of all API requests made with a context
+// returned by CollectTraceIDs.
+type TraceIDs struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// CollectTraceIDs returns a context that records the trace ID of every request
+// made with it, including the sub-requests of composite operations such as
+// GetAll or GetItems, so one logical operation can be matched to all of its
+// server-side traces.
+func CollectTraceIDs(ctx context.Context) (context.Context, *TraceIDs) {
+	t := &TraceIDs{}
+	return context.WithValue(ctx, traceCollectorKey{}, t), t
+}
+
+// List returns the collected trace IDs in request completion order.
+func (t *TraceIDs) List() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.ids...)
+}
+
+func (t *TraceIDs) add(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ids = append(t.ids, id)
+}
+
+// recordTraceID adds id to the collector carried by ctx, if any.
+func recordTraceID(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	if t, ok := ctx.Value(traceCollectorKey{}).(*TraceIDs); ok {
+		t.add(id)
+	}
+}
+
+// detachedContext keeps the values of its parent, such as request IDs and
+// trace collectors, but not its deadline or cancellation. It is used for
+// cleanup requests that must run after the caller's context is done.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
@@ -0,0 +1,106 @@
+package carthooks
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts a span for each HTTP request the client sends. The SDK has no
+// dependencies, so it does not import OpenTelemetry itself, but a Tracer is
+// straightforward to back with one:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string, attrs ...carthooks.Attribute) (context.Context, func(carthooks.RequestInfo)) {
+//		ctx, span := o.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		for _, a := range attrs {
+//			span.SetAttributes(attribute.String(a.Key, fmt.Sprint(a.Value)))
+//		}
+//		return ctx, func(info carthooks.RequestInfo) {
+//			span.SetAttributes(attribute.Int("http.response.status_code", info.StatusCode))
+//			if info.Err != nil {
+//				span.SetStatus(codes.Error, info.Err.Error())
+//			}
+//			span.End()
+//		}
+//	}
+//
+// The context returned by Start is the one the request is made with, so an
+// interceptor (WithRequestInterceptor) can inject the span's trace headers,
+// e.g. with otel.GetTextMapPropagator().Inject(req.Context(),
+// propagation.HeaderCarrier(req.Header)). Request duration and status metrics
+// can be recorded from WithObserver.
+//
+// There are no WithTracerProvider or WithMeterProvider options taking
+// OpenTelemetry providers directly, and the SDK does not propagate trace
+// headers itself: Tracer, an interceptor and WithObserver are the extension
+// points, wired up by the caller as above.
+type Tracer interface {
+	// Start begins a span named name and returns the context to make the
+	// request with, and a function that ends the span with the outcome.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, func(RequestInfo))
+}
+
+// WithTracer makes the client start a span with t around every HTTP request,
+// named after the method and route, e.g.
+// "GET /v1/apps/:id/collections/:id/items". The span attributes carry the
+// method, the URL, the app, collection and item IDs found in the path, the
+// SDK operation and the attempt. Without a tracer no spans are started.
+func WithTracer(t Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
+// startSpan starts the span of a request with the tracer, if one is set.
+func (c *Client) startSpan(ctx context.Context, method, rawURL string, attempt int) (context.Context, func(RequestInfo)) {
+	if c.tracer == nil {
+		return ctx, func(RequestInfo) {}
+	}
+	attrs := []Attribute{
+		{Key: "http.request.method", Value: method},
+		{Key: "url.full", Value: rawURL},
+	}
+	attrs = append(attrs, resourceAttributes(rawURL)...)
+	if op := operationFromContext(ctx); op != "" {
+		attrs = append(attrs, Attribute{Key: "carthooks.operation", Value: op})
+	}
+	if attempt > 1 {
+		attrs = append(attrs, Attribute{Key: "carthooks.attempt", Value: attempt})
+	}
+	return c.tracer.Start(ctx, method+" "+routeOf(rawURL), attrs...)
+}
+
+// resourceAttributes returns the app, collection and item IDs in the path of
+// rawURL as span attributes.
+func resourceAttributes(rawURL string) []Attribute {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	names := map[string]string{
+		"apps":        "carthooks.app_id",
+		"collections": "carthooks.collection_id",
+		"items":       "carthooks.item_id",
+	}
+	var attrs []Attribute
+	segments := strings.Split(u.Path, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		key, ok := names[segments[i]]
+		if !ok {
+			continue
+		}
+		if id, err := strconv.Atoi(segments[i+1]); err == nil {
+			attrs = append(attrs, Attribute{Key: key, Value: id})
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,298 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// txRollbackTimeout bounds the rollback of a best-effort transaction, which
+// runs even when the caller's context is done.
+const txRollbackTimeout = 30 * time.Second
+
+// TxOpKind is the kind of a transaction operation.
+type TxOpKind string
+
+const (
+	TxCreate TxOpKind = "create"
+	TxUpdate TxOpKind = "update"
+	TxDelete TxOpKind = "delete"
+)
+
+// TxOp is one write of a transaction.
+type TxOp struct {
+	Kind         TxOpKind
+	AppID        int
+	CollectionID int
+	// ItemID is zero for creates.
+	ItemID int
+	Data   map[string]interface{}
+}
+
+// Tx collects the writes of a transaction; see Client.Transaction.
+type Tx struct {
+	ops []TxOp
+}
+
+// Create adds the creation of an item.
+func (tx *Tx) Create(appID, collectionID int, data map[string]interface{}) {
+	tx.ops = append(tx.ops, TxOp{Kind: TxCreate, AppID: appID, CollectionID: collectionID, Data: data})
+}
+
+// Update adds an update setting the given fields of an item, leaving the
+// others as they are.
+func (tx *Tx) Update(appID, collectionID, itemID int, data map[string]interface{}) {
+	tx.ops = append(tx.ops, TxOp{Kind: TxUpdate, AppID: appID, CollectionID: collectionID, ItemID: itemID, Data: data})
+}
+
+// Delete adds the deletion of an item.
+func (tx *Tx) Delete(appID, collectionID, itemID int) {
+	tx.ops = append(tx.ops, TxOp{Kind: TxDelete, AppID: appID, CollectionID: collectionID, ItemID: itemID})
+}
+
+// TxOption customizes Transaction.
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	bestEffort bool
+}
+
+// TxBestEffort lets Transaction fall back to applying the writes one by one
+// when the server has no transaction endpoint; see Transaction.
+func TxBestEffort() TxOption {
+	return func(o *txOptions) {
+		o.bestEffort = true
+	}
+}
+
+// TxOutcome is the outcome of one operation of a transaction.
+type TxOutcome struct {
+	Op TxOp
+	// Applied reports whether the operation took effect, and was not
+	// rolled back since.
+	Applied bool
+	// Item is the created item of a create.
+	Item *Item
+	Err  error
+	// RolledBack reports whether the operation was undone after a later
+	// one failed; RollbackErr is why undoing it failed.
+	RolledBack  bool
+	RollbackErr error
+}
+
+// TxResult reports the outcome of a transaction, one entry per operation in
+// the order they were added.
+type TxResult struct {
+	// Atomic reports whether the server applied the transaction as a whole.
+	Atomic   bool
+	Outcomes []TxOutcome
+}
+
+// TxError reports the operation that made a transaction fail.
+type TxError struct {
+	// Index is the position of the failed operation, or -1 if the
+	// transaction failed as a whole.
+	Index int
+	Err   error
+	// Incomplete reports that some applied operations could not be rolled
+	// back; see TxOutcome.RollbackErr.
+	Incomplete bool
+}
+
+func (e *TxError) Error() string {
+	msg := fmt.Sprintf("carthooks: transaction failed: %v", e.Err)
+	if e.Index >= 0 {
+		msg = fmt.Sprintf("carthooks: transaction operation %d failed: %v", e.Index, e.Err)
+	}
+	if e.Incomplete {
+		msg += " (rollback incomplete)"
+	}
+	return msg
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// Transaction calls fn to collect writes on a Tx, then submits them. Nothing
+// is sent if fn returns an error.
+//
+// The writes are sent to the API's transaction endpoint, which applies them
+// all or none. With TxBestEffort, a server answering 404, 405 or 501 is taken
+// to have no such endpoint, and the writes are applied one by one instead:
+// when one fails, those applied before it are undone in reverse order.
+// Created items are deleted, updated fields restored, fields the update added
+// removed, and deleted items created again, under new IDs. Such a rollback
+// runs even if ctx is done, but is not atomic: other clients may see the
+// intermediate states. Since a transaction referring to a missing item may
+// be answered with 404 too, only use TxBestEffort when that is acceptable.
+//
+// A failed transaction returns a *TxError along with the result.
+func (c *Client) Transaction(ctx context.Context, fn func(tx *Tx) error, opts ...TxOption) (*TxResult, error) {
+	ctx = withOperation(ctx, "Transaction")
+	var o txOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tx := &Tx{}
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+	result := &TxResult{Outcomes: make([]TxOutcome, len(tx.ops))}
+	for i, op := range tx.ops {
+		result.Outcomes[i].Op = op
+	}
+	if len(tx.ops) == 0 {
+		return result, nil
+	}
+	err := c.atomicTransaction(ctx, result, o.bestEffort)
+	if !o.bestEffort || !transactionUnsupported(err) {
+		return result, err
+	}
+	c.logger.Debug("carthooks: no transaction endpoint, applying operations one by one", "operations", len(tx.ops))
+	return result, c.compensatedTransaction(ctx, result)
+}
+
+// atomicTransaction posts the operations to the transaction endpoint. If
+// fallback is set, an error for a missing endpoint is returned as it is,
+// leaving the outcomes unset.
+func (c *Client) atomicTransaction(ctx context.Context, result *TxResult, fallback bool) error {
+	ops := make([]interface{}, len(result.Outcomes))
+	for i, o := range result.Outcomes {
+		op := map[string]interface{}{"op": string(o.Op.Kind), "appId": o.Op.AppID, "collectionId": o.Op.CollectionID}
+		if o.Op.ItemID != 0 {
+			op["itemId"] = o.Op.ItemID
+		}
+		if o.Op.Data != nil {
+			data, err := c.encodeFields(o.Op.Data)
+			if err != nil {
+				return &TxError{Index: i, Err: err}
+			}
+			op["data"] = data
+		}
+		ops[i] = op
+	}
+	urladdr := fmt.Sprintf("%s/v1/transactions", c.baseUrl)
+	rsp, err := c.do(ctx, http.MethodPost, urladdr, jsonBody{"operations": ops}, nil)
+	if err != nil {
+		if fallback && transactionUnsupported(err) {
+			return err
+		}
+		for i := range result.Outcomes {
+			result.Outcomes[i].Err = err
+		}
+		return &TxError{Index: -1, Err: err}
+	}
+	result.Atomic = true
+	for i := range result.Outcomes {
+		result.Outcomes[i].Applied = true
+	}
+	var applied struct {
+		Results []*Item `json:"results"`
+	}
+	if err := rsp.Bind(&applied); err != nil {
+		return fmt.Errorf("carthooks: transaction applied, but reading its results failed: %w", err)
+	}
+	for i := range result.Outcomes {
+		o := &result.Outcomes[i]
+		if i < len(applied.Results) && o.Op.Kind == TxCreate {
+			o.Item = applied.Results[i]
+		}
+	}
+	return nil
+}
+
+// transactionUnsupported reports whether err may say the server has no
+// transaction endpoint.
+func transactionUnsupported(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// compensatedTransaction applies the operations in turn, rolling back the
+// applied ones when one fails.
+func (c *Client) compensatedTransaction(ctx context.Context, result *TxResult) error {
+	undo := make([]func(context.Context) error, len(result.Outcomes))
+	for i := range result.Outcomes {
+		o := &result.Outcomes[i]
+		undo[i], o.Err = c.applyTxOp(ctx, o)
+		if o.Err == nil {
+			o.Applied = true
+			continue
+		}
+		txErr := &TxError{Index: i, Err: o.Err}
+		rollbackCtx, cancel := context.WithTimeout(detach(ctx), txRollbackTimeout)
+		defer cancel()
+		for j := i - 1; j >= 0; j-- {
+			prev := &result.Outcomes[j]
+			if prev.RollbackErr = undo[j](rollbackCtx); prev.RollbackErr != nil {
+				txErr.Incomplete = true
+				continue
+			}
+			prev.Applied, prev.RolledBack = false, true
+		}
+		return txErr
+	}
+	return nil
+}
+
+// applyTxOp applies one operation and returns how to undo it. Items are read
+// before they are updated or deleted, so they can be restored.
+func (c *Client) applyTxOp(ctx context.Context, o *TxOutcome) (func(context.Context) error, error) {
+	op := o.Op
+	fresh := ContextWithRequestOptions(ctx, WithNoCache())
+	switch op.Kind {
+	case TxCreate:
+		item, err := c.CreateItemContext(ctx, op.AppID, op.CollectionID, op.Data)
+		if err != nil {
+			return nil, err
+		}
+		o.Item = item
+		return func(ctx context.Context) error {
+			_, err := c.DeleteItemContext(ctx, op.AppID, op.CollectionID, item.ID)
+			return err
+		}, nil
+	case TxUpdate:
+		before, err := c.GetItemByIDContext(fresh, op.AppID, op.CollectionID, op.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := c.PatchItem(ctx, op.AppID, op.CollectionID, op.ItemID, op.Data); err != nil {
+			return nil, err
+		}
+		restore := make(map[string]interface{}, len(op.Data))
+		for field := range op.Data {
+			if value, ok := before.Fields[field]; ok {
+				restore[field] = value
+			} else {
+				restore[field] = Unset()
+			}
+		}
+		return func(ctx context.Context) error {
+			_, err := c.PatchItem(ctx, op.AppID, op.CollectionID, op.ItemID, restore)
+			return err
+		}, nil
+	case TxDelete:
+		before, err := c.GetItemByIDContext(fresh, op.AppID, op.CollectionID, op.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := c.DeleteItemContext(ctx, op.AppID, op.CollectionID, op.ItemID); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			_, err := c.CreateItemContext(ctx, op.AppID, op.CollectionID, before.Fields)
+			return err
+		}, nil
+	}
+	return nil, fmt.Errorf("carthooks: unknown transaction operation %q", op.Kind)
+}
@@ -0,0 +1,77 @@
+package carthooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestTransactionNeedsBestEffortWithoutEndpoint(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	res, err := s.Client().Transaction(context.Background(), func(tx *carthooks.Tx) error {
+		tx.Create(1, 2, map[string]interface{}{"n": 1})
+		return nil
+	})
+	var txErr *carthooks.TxError
+	if !errors.As(err, &txErr) || txErr.Index != -1 {
+		t.Fatalf("got %v, want a *TxError for the whole transaction", err)
+	}
+	if res.Outcomes[0].Applied || len(s.Items(1, 2)) != 0 {
+		t.Error("operations were applied without TxBestEffort")
+	}
+}
+
+func TestTransactionBestEffortRollsBack(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	a := s.AddItem(1, 2, map[string]interface{}{"n": 1, "keep": "a"})
+	b := s.AddItem(1, 2, map[string]interface{}{"n": 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The caller gives up as the failing operation is sent; the rollback
+	// must still run.
+	cancelOnMissing := func(next carthooks.RoundTripFunc) carthooks.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/items/999") {
+				cancel()
+			}
+			return next(req)
+		}
+	}
+	c := s.Client(carthooks.WithMiddleware(cancelOnMissing))
+	res, err := c.Transaction(ctx, func(tx *carthooks.Tx) error {
+		tx.Create(1, 2, map[string]interface{}{"n": 3})
+		tx.Update(1, 2, a.ID, map[string]interface{}{"n": 10, "added": true})
+		tx.Delete(1, 2, b.ID)
+		tx.Update(1, 2, 999, map[string]interface{}{"n": 0})
+		return nil
+	}, carthooks.TxBestEffort())
+
+	var txErr *carthooks.TxError
+	if !errors.As(err, &txErr) || txErr.Index != 3 || txErr.Incomplete {
+		t.Fatalf("got %v, want a complete rollback after operation 3", err)
+	}
+	for i, o := range res.Outcomes[:3] {
+		if o.Applied || !o.RolledBack || o.RollbackErr != nil {
+			t.Errorf("operation %d: %+v, want rolled back", i, o)
+		}
+	}
+	items := s.Items(1, 2)
+	if len(items) != 2 {
+		t.Fatalf("got %d items after rollback, want 2: %v", len(items), items)
+	}
+	if got, want := items[0].Fields, map[string]interface{}{"n": float64(1), "keep": "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("updated item restored as %v, want %v", got, want)
+	}
+	if items[1].Fields["n"] != float64(2) {
+		t.Errorf("deleted item restored as %v", items[1].Fields)
+	}
+}
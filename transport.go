@@ -0,0 +1,72 @@
+package carthooks
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Connection pool settings of the default transport. Go's default of two
+// idle connections per host makes busy clients open and close connections
+// constantly, leaving sockets in TIME_WAIT.
+const (
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// maxDrain bounds how much of an unread response body is discarded on close
+// so that the connection can be reused. Larger remainders are cheaper to
+// abandon with the connection.
+const maxDrain = 256 << 10
+
+// WithTransport makes the default HTTP client send requests through rt, e.g.
+// an *http.Transport with its own pool limits or proxy. It is ignored when
+// WithHTTPClient is used; set the client's Transport instead.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// setupHTTPClient builds the default HTTP client unless one was given, and
+// makes it fail every request if the options were invalid.
+func (c *Client) setupHTTPClient() {
+	c.checkNetworkOptions()
+	if c.httpClient == nil {
+		if c.transport == nil {
+			c.transport = c.newTransport()
+		}
+		c.httpClient = &http.Client{Timeout: c.timeout, Transport: c.transport}
+	}
+	c.setupRecorder()
+	if c.err != nil {
+		c.httpClient = &http.Client{Transport: failingTransport{c.err}}
+	}
+}
+
+// newTransport returns the transport of the default HTTP client: Go's
+// default transport with a larger idle pool, sized to at least the
+// concurrency limit set with WithMaxConcurrentRequests.
+func (c *Client) newTransport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: defaultKeepAlive}).DialContext
+	t.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if n := cap(c.slots); n > t.MaxIdleConnsPerHost {
+		t.MaxIdleConnsPerHost = n
+	}
+	if t.MaxIdleConns < t.MaxIdleConnsPerHost {
+		t.MaxIdleConns = t.MaxIdleConnsPerHost
+	}
+	t.IdleConnTimeout = defaultIdleConnTimeout
+	c.network.apply(t)
+	return t
+}
+
+// drainAndClose discards what is left of body, up to maxDrain bytes, and
+// closes it, so the connection goes back to the pool.
+func drainAndClose(body io.ReadCloser) error {
+	io.CopyN(io.Discard, body, maxDrain)
+	return body.Close()
+}
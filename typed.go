@@ -0,0 +1,39 @@
+package carthooks
+
+import "context"
+
+// QueryItems runs the query and decodes the fields of each item into a T, a
+// struct matched by json tag or the client's field naming as in DecodeItem;
+// a field tagged "id" receives the item ID. Field codecs are applied before
+// decoding. Use Query.GetContext for dynamic fields.
+//
+// The binding is a convenience, not an allocation saving: each item is still
+// decoded into an Item first, so that codecs and field naming apply as they
+// do for DecodeItem.
+func QueryItems[T any](ctx context.Context, q *Query) ([]T, error) {
+	items, err := q.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(items))
+	for i := range items {
+		if err := q.client.DecodeItem(&items[i], &out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// GetItemByID reads a single item like Client.GetItemByIDContext and decodes
+// its fields into a T, as QueryItems does.
+func GetItemByID[T any](ctx context.Context, c *Client, appID, collectionID, itemID int, opts ...ItemOption) (*T, error) {
+	item, err := c.GetItemByIDContext(ctx, appID, collectionID, itemID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	v := new(T)
+	if err := c.DecodeItem(item, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
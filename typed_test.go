@@ -0,0 +1,45 @@
+package carthooks_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+type ticket struct {
+	ID     int     `json:"id"`
+	Title  string  `json:"title"`
+	Amount float64 `json:"amount"`
+}
+
+func TestTypedItems(t *testing.T) {
+	s := carthookstest.NewServer()
+	defer s.Close()
+	a := s.AddItem(1, 2, map[string]interface{}{"title": "a", "amount": 3})
+	b := s.AddItem(1, 2, map[string]interface{}{"title": "b", "amount": 5})
+	c := s.Client(carthooks.WithFieldCodec("title", nil, func(v interface{}) (interface{}, error) {
+		return strings.ToUpper(v.(string)), nil
+	}))
+	ctx := context.Background()
+
+	got, err := carthooks.QueryItems[ticket](ctx, c.Query(1, 2).OrderBy("amount", carthooks.Asc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ticket{{ID: a.ID, Title: "A", Amount: 3}, {ID: b.ID, Title: "B", Amount: 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryItems got %+v, want %+v", got, want)
+	}
+
+	one, err := carthooks.GetItemByID[ticket](ctx, c, 1, 2, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *one != want[1] {
+		t.Errorf("GetItemByID got %+v, want %+v", *one, want[1])
+	}
+}
@@ -0,0 +1,340 @@
+package carthooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when the checksum reported by the storage
+// endpoint differs from the one computed while uploading.
+var ErrChecksumMismatch = errors.New("carthooks: upload checksum mismatch")
+
+// UploadResult describes a file uploaded with UploadFile.
+type UploadResult struct {
+	// FileID identifies the file in attachment field values.
+	FileID      string
+	Name        string
+	ContentType string
+	Size        int64
+	// SHA256 is the hex-encoded SHA-256 of the uploaded bytes, computed
+	// while streaming.
+	SHA256 string
+}
+
+// uploadToken is the data returned by the upload token endpoint.
+type uploadToken struct {
+	URL     string            `json:"uploadUrl"`
+	Method  string            `json:"method"`
+	FileID  string            `json:"fileId"`
+	Headers map[string]string `json:"headers"`
+	// Fields are the form fields of a multipart upload.
+	Fields map[string]string `json:"fields"`
+}
+
+// UnmarshalJSON also accepts the snake_case and short key names used by
+// older deployments, and a token nested under "token".
+func (t *uploadToken) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if nested, ok := raw["token"]; ok && len(raw) == 1 {
+		return t.UnmarshalJSON(nested)
+	}
+	str := func(keys ...string) string {
+		for _, key := range keys {
+			var s string
+			if json.Unmarshal(raw[key], &s) == nil && s != "" {
+				return s
+			}
+			var n json.Number
+			if json.Unmarshal(raw[key], &n) == nil && n != "" {
+				return n.String()
+			}
+		}
+		return ""
+	}
+	t.URL = str("uploadUrl", "upload_url", "url")
+	t.Method = str("method")
+	t.FileID = str("fileId", "file_id", "id", "key")
+	if h, ok := raw["headers"]; ok {
+		if err := json.Unmarshal(h, &t.Headers); err != nil {
+			return fmt.Errorf("carthooks: upload token headers: %w", err)
+		}
+	}
+	if f, ok := raw["fields"]; ok {
+		if err := json.Unmarshal(f, &t.Fields); err != nil {
+			return fmt.Errorf("carthooks: upload token fields: %w", err)
+		}
+	}
+	return nil
+}
+
+// Attachment returns the uploaded file as an attachment field value, ready to
+// be stored with CreateItem or UpdateItem.
+func (r *UploadResult) Attachment() Attachment {
+	return Attachment{ID: r.FileID, Name: r.Name, Size: r.Size, MimeType: r.ContentType}
+}
+
+// UploadOption customizes UploadFile, UploadMultipart and UploadResumable.
+type UploadOption func(*uploadOptions)
+
+type uploadOptions struct {
+	progress func(sent, total int64)
+
+	// Settings of UploadResumable.
+	partSize    int64
+	concurrency int
+	bandwidth   int64
+	store       UploadStore
+	storeKey    string
+}
+
+// WithUploadProgress calls fn as the content is sent, with the number of
+// bytes sent so far and the total size, or -1 if the size of the reader is
+// not known in advance. fn runs on the goroutine reading the content and must
+// not block.
+func WithUploadProgress(fn func(sent, total int64)) UploadOption {
+	return func(o *uploadOptions) {
+		o.progress = fn
+	}
+}
+
+// UploadFile uploads the content of r to file storage and returns the file
+// reference to store in an attachment field, as UploadResult.FileID. The
+// storage endpoint and method come from the upload token (GetUploadToken).
+// The content is streamed, not buffered; its SHA-256 is computed on the way
+// and, if the storage endpoint reports a checksum, compared with it, failing
+// with ErrChecksumMismatch on disagreement.
+func (c *Client) UploadFile(ctx context.Context, r io.Reader, filename, contentType string, opts ...UploadOption) (*UploadResult, error) {
+	token, err := c.uploadToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	method := token.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+	u := newUpload(r, filename, contentType, opts)
+	return c.upload(ctx, token, method, u.body, u.size, contentType, u)
+}
+
+// UploadMultipart uploads the content of r like UploadFile, but as the "file"
+// part of a multipart/form-data POST, the form S3-style presigned POST
+// endpoints expect. Form fields listed in the upload token are sent before
+// the file.
+func (c *Client) UploadMultipart(ctx context.Context, r io.Reader, filename, contentType string, opts ...UploadOption) (*UploadResult, error) {
+	token, err := c.uploadToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	method := token.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	u := newUpload(r, filename, contentType, opts)
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, token.Fields, u.body, filename, contentType))
+	}()
+	defer pr.Close()
+	return c.upload(ctx, token, method, pr, -1, mw.FormDataContentType(), u)
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, r io.Reader, filename, contentType string) error {
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+func (c *Client) uploadToken(ctx context.Context) (*uploadToken, error) {
+	rsp, err := c.GetUploadTokenContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token := &uploadToken{}
+	if err := rsp.Bind(token); err != nil {
+		return nil, err
+	}
+	if token.URL == "" {
+		return nil, errors.New("carthooks: upload token has no upload URL")
+	}
+	return token, nil
+}
+
+// upload is the content of an upload in flight: the file bytes pass through
+// body, which hashes and counts them.
+type upload struct {
+	body        *countingReader
+	sum         hash.Hash
+	size        int64
+	name        string
+	contentType string
+}
+
+func newUpload(r io.Reader, filename, contentType string, opts []UploadOption) *upload {
+	o := uploadOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	u := &upload{sum: sha256.New(), size: sizeOf(r), name: filename, contentType: contentType}
+	u.body = &countingReader{r: io.TeeReader(r, u.sum)}
+	if o.progress != nil {
+		size := u.size
+		u.body.progress = func(n int64) { o.progress(n, size) }
+	}
+	return u
+}
+
+// upload sends body to the storage endpoint of token and checks the result.
+func (c *Client) upload(ctx context.Context, token *uploadToken, method string, body io.Reader, size int64, bodyType string, u *upload) (*UploadResult, error) {
+	req, err := http.NewRequestWithContext(ctx, method, token.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for key, value := range token.Headers {
+		req.Header.Set(key, value)
+	}
+	if bodyType != "" {
+		req.Header.Set("Content-Type", bodyType)
+	}
+	var sign func(*http.Request) error
+	if c.isAPIHost(req.URL) {
+		if err := c.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		sign = c.signWith(nil)
+	}
+
+	start := c.clock.Now()
+	info := RequestInfo{Method: method, URL: token.URL, Route: routeOf(token.URL), Operation: operationFromContext(ctx), Attempt: 1}
+	resp, err := c.send(ctx, req, sign, &info)
+	if err != nil {
+		info.Duration, info.Err = c.clock.Now().Sub(start), err
+		if err != ErrCircuitOpen {
+			c.observe(info)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	info.StatusCode = resp.StatusCode
+	info.Duration = c.clock.Now().Sub(start)
+	info.RequestBytes, info.ResponseBytes = u.body.n, int64(len(data))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		info.Err = &APIError{StatusCode: resp.StatusCode, Body: data}
+		c.observe(info)
+		return nil, info.Err
+	}
+	c.observe(info)
+
+	result := &UploadResult{
+		FileID:      token.FileID,
+		Name:        u.name,
+		ContentType: u.contentType,
+		Size:        u.body.n,
+		SHA256:      hex.EncodeToString(u.sum.Sum(nil)),
+	}
+	var uploaded struct {
+		Data struct {
+			ID     string `json:"id"`
+			SHA256 string `json:"sha256"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(data, &uploaded) == nil && uploaded.Data.ID != "" {
+		result.FileID = uploaded.Data.ID
+	}
+	if err := verifyChecksum(u.sum, uploaded.Data.SHA256, resp.Header); err != nil {
+		return nil, err
+	}
+	if result.FileID == "" {
+		return nil, errors.New("carthooks: upload did not return a file ID")
+	}
+	return result, nil
+}
+
+// verifyChecksum compares the computed digest with the one the storage
+// endpoint reported in the body or headers, hex or base64 encoded.
+func verifyChecksum(sum hash.Hash, reported string, header http.Header) error {
+	if reported == "" {
+		reported = header.Get("X-Checksum-Sha256")
+	}
+	if reported == "" {
+		reported = header.Get("X-Amz-Checksum-Sha256")
+	}
+	if reported == "" {
+		return nil
+	}
+	want := sum.Sum(nil)
+	got, err := hex.DecodeString(reported)
+	if err != nil {
+		got, err = base64.StdEncoding.DecodeString(reported)
+	}
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: computed %s, server reported %s", ErrChecksumMismatch,
+			hex.EncodeToString(want), strings.TrimSpace(reported))
+	}
+	return nil
+}
+
+// sizeOf returns the length of r if it can be known without reading it, or
+// -1 otherwise.
+func sizeOf(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		if fi, err := v.Stat(); err == nil && fi.Mode().IsRegular() {
+			if pos, err := v.Seek(0, io.SeekCurrent); err == nil {
+				return fi.Size() - pos
+			}
+		}
+	}
+	return -1
+}
+
+type countingReader struct {
+	r        io.Reader
+	n        int64
+	progress func(n int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if n > 0 && c.progress != nil {
+		c.progress(c.n)
+	}
+	return n, err
+}
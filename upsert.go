@@ -0,0 +1,151 @@
+package carthooks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrAmbiguousMatch is returned by UpsertItem and FindOrCreateItem when more
+// than one item matches.
+var ErrAmbiguousMatch = errors.New("carthooks: more than one item matches")
+
+// UpsertOption customizes UpsertItem.
+type UpsertOption func(*upsertOptions)
+
+type upsertOptions struct {
+	lockTimeout int
+}
+
+// UpsertLocked makes UpsertItem update an existing item under a lock held
+// for up to timeout seconds, as UpdateItemLocked does, but patching it.
+func UpsertLocked(timeout int) UpsertOption {
+	return func(o *upsertOptions) {
+		o.lockTimeout = timeout
+	}
+}
+
+// UpsertItem sets the fields of data on the item whose fields equal match,
+// leaving its other fields as they are, or creates one from match and data
+// if there is none, and reports whether it created it. The match fields
+// should be unique in the collection; if several items match, nothing is
+// written and the error matches ErrAmbiguousMatch.
+//
+// The create is sent with an idempotency key derived from the match, so
+// concurrent upserts of the same record create it once where the server
+// honors the key. The server answers the later creates with the item of the
+// first, so every one of them reports created, but only the first one's data
+// is stored; upsert again to apply the others. A record deleted and upserted
+// again within the server's key retention may not be recreated.
+func (c *Client) UpsertItem(ctx context.Context, appID, collectionID int, match, data map[string]interface{}, opts ...UpsertOption) (item *Item, created bool, err error) {
+	ctx = withOperation(ctx, "UpsertItem")
+	var o upsertOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	found, err := c.findMatch(ctx, appID, collectionID, match)
+	if err != nil {
+		return nil, false, err
+	}
+	if found == nil {
+		item, err = c.createMatch(ctx, appID, collectionID, match, data)
+		return item, err == nil, err
+	}
+	if o.lockTimeout > 0 {
+		item, err = c.writeLocked(ctx, appID, collectionID, found.ID, o.lockTimeout, func() (*Response, error) {
+			return c.PatchItem(ctx, appID, collectionID, found.ID, data)
+		})
+		return item, false, err
+	}
+	rsp, err := c.PatchItem(ctx, appID, collectionID, found.ID, data)
+	if err != nil {
+		return nil, false, err
+	}
+	item = &Item{}
+	if err := rsp.Bind(item); err != nil || item.ID == 0 {
+		// Without the stored item in the response, report the change on
+		// the copy read before.
+		for field, value := range data {
+			found.Fields[field] = value
+		}
+		item = found
+	}
+	return item, false, nil
+}
+
+// FindOrCreateItem returns the item whose fields equal match, or creates one
+// from match and data if there is none, and reports whether it created it.
+// An existing item is left unchanged. Several matches and concurrent calls
+// are handled as in UpsertItem.
+func (c *Client) FindOrCreateItem(ctx context.Context, appID, collectionID int, match, data map[string]interface{}) (item *Item, created bool, err error) {
+	ctx = withOperation(ctx, "FindOrCreateItem")
+	found, err := c.findMatch(ctx, appID, collectionID, match)
+	if err != nil || found != nil {
+		return found, false, err
+	}
+	item, err = c.createMatch(ctx, appID, collectionID, match, data)
+	return item, err == nil, err
+}
+
+// findMatch returns the single item whose fields equal match, or nil.
+func (c *Client) findMatch(ctx context.Context, appID, collectionID int, match map[string]interface{}) (*Item, error) {
+	if len(match) == 0 {
+		return nil, errors.New("carthooks: no match fields given")
+	}
+	q := c.Query(appID, collectionID).Limit(2).WithoutCount()
+	for field, value := range match {
+		q.Where(field, OpEq, value)
+	}
+	items, err := q.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch len(items) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &items[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrAmbiguousMatch, match)
+	}
+}
+
+// createMatch creates an item from match and data, keyed by the match.
+func (c *Client) createMatch(ctx context.Context, appID, collectionID int, match, data map[string]interface{}) (*Item, error) {
+	fields := make(map[string]interface{}, len(match)+len(data))
+	for field, value := range data {
+		fields[field] = value
+	}
+	for field, value := range match {
+		fields[field] = value
+	}
+	key, err := matchKey(appID, collectionID, match)
+	if err != nil {
+		return nil, err
+	}
+	item, _, err := c.CreateItemWithResponse(ctx, appID, collectionID, fields, WithIdempotencyKey(key))
+	return item, err
+}
+
+// matchKey derives an idempotency key from the match of an upsert.
+func matchKey(appID, collectionID int, match map[string]interface{}) (string, error) {
+	fields := make([]string, 0, len(match))
+	for field := range match {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	h := sha256.New()
+	fmt.Fprintf(h, "upsert:%d:%d", appID, collectionID)
+	for _, field := range fields {
+		value, err := json.Marshal(match[field])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, ":%q=%s", field, value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
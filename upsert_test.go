@@ -0,0 +1,44 @@
+package carthooks_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/carthookstest"
+)
+
+func TestUpsertItemKeepsOtherFields(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []carthooks.UpsertOption
+	}{
+		{"unlocked", nil},
+		{"locked", []carthooks.UpsertOption{carthooks.UpsertLocked(10)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := carthookstest.NewServer()
+			defer s.Close()
+			item := s.AddItem(1, 2, map[string]interface{}{"sku": "A-1", "stock": 3, "title": "keep me"})
+
+			_, created, err := s.Client().UpsertItem(context.Background(), 1, 2,
+				map[string]interface{}{"sku": "A-1"}, map[string]interface{}{"stock": 5}, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created {
+				t.Error("created = true for an existing item")
+			}
+			got, _ := s.Item(1, 2, item.ID)
+			if want := map[string]interface{}{"sku": "A-1", "stock": float64(5), "title": "keep me"}; !reflect.DeepEqual(got.Fields, want) {
+				t.Errorf("got %v, want %v", got.Fields, want)
+			}
+			for _, r := range s.Requests() {
+				if r.Method == "PUT" {
+					t.Errorf("UpsertItem sent PUT %s", r.Path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package carthooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// User is a Carthooks user.
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Role is a set of permissions members of an app can be given.
+type Role struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Permissions lists what the role allows, e.g. "items.read".
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Member is a user's membership of an app.
+type Member struct {
+	User User `json:"user"`
+	Role Role `json:"role"`
+	// Status is "active" for members who accepted their invitation and
+	// "invited" for those who have not yet.
+	Status    string    `json:"status"`
+	InvitedAt time.Time `json:"invitedAt,omitempty"`
+	JoinedAt  time.Time `json:"joinedAt,omitempty"`
+}
+
+// Identity is who an access token acts for, as returned by Me.
+type Identity struct {
+	User User `json:"user"`
+	// Scopes are the scopes granted to the token, if it is scoped.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Me returns the identity of the client's access token.
+func (c *Client) Me(ctx context.Context) (*Identity, error) {
+	rsp, err := c.GetContext(ctx, c.baseUrl+"/v1/me")
+	if err != nil {
+		return nil, err
+	}
+	identity := &Identity{}
+	if err := rsp.Bind(identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// ListRoles returns the roles members of an app can be given.
+func (c *Client) ListRoles(ctx context.Context, appID int) ([]Role, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/roles", c.baseUrl, appID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	roles := []Role{}
+	if err := rsp.Bind(&roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ListMembers returns the members of an app, including pending invitations.
+func (c *Client) ListMembers(ctx context.Context, appID int) ([]Member, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/members", c.baseUrl, appID)
+	rsp, err := c.GetContext(ctx, urladdr)
+	if err != nil {
+		return nil, err
+	}
+	members := []Member{}
+	if err := rsp.Bind(&members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// InviteMember invites the user with the given email to an app with the
+// given role. The user becomes a member once they accept.
+func (c *Client) InviteMember(ctx context.Context, appID int, email, roleID string) (*Member, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/members", c.baseUrl, appID)
+	body := map[string]interface{}{"email": email, "roleId": roleID}
+	return c.memberRequest(ctx, http.MethodPost, urladdr, body)
+}
+
+// SetMemberRole gives a member of an app another role.
+func (c *Client) SetMemberRole(ctx context.Context, appID, userID int, roleID string) (*Member, error) {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/members/%d", c.baseUrl, appID, userID)
+	return c.memberRequest(ctx, http.MethodPut, urladdr, map[string]interface{}{"roleId": roleID})
+}
+
+// RemoveMember revokes a user's access to an app, or withdraws their
+// invitation.
+func (c *Client) RemoveMember(ctx context.Context, appID, userID int) error {
+	urladdr := fmt.Sprintf("%s/v1/apps/%d/members/%d", c.baseUrl, appID, userID)
+	_, err := c.do(ctx, http.MethodDelete, urladdr, nil, nil)
+	return err
+}
+
+func (c *Client) memberRequest(ctx context.Context, method, urladdr string, data map[string]interface{}) (*Member, error) {
+	rsp, err := c.do(ctx, method, urladdr, jsonBody(c.envelope(data)), nil)
+	if err != nil {
+		return nil, err
+	}
+	member := &Member{}
+	if err := rsp.Bind(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,67 @@
+package carthooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ValidationError reports a query the server rejected as invalid. Field and
+// Operator name the offending filter when it can be identified from the
+// server's message.
+type ValidationError struct {
+	StatusCode int
+	Field      string
+	Operator   string
+	Key        string
+	Message    string
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+func (e *ValidationError) Error() string {
+	msg := "carthooks: invalid query"
+	if e.Field != "" {
+		msg += " filter " + e.Field
+		if e.Operator != "" {
+			msg += " " + e.Operator
+		}
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	} else if e.Key != "" {
+		msg += ": " + e.Key
+	}
+	return msg
+}
+
+// Validate asks the server whether the query is acceptable by running it for
+// a single item without counting totals. A rejection (400 or 422) is
+// returned as a *ValidationError; other failures are returned as they are.
+func (q *Query) Validate(ctx context.Context) error {
+	params := q.params()
+	params.Set("pagination[pageSize]", "1")
+	params.Set("pagination[withCount]", "false")
+	_, _, err := q.fetch(ctx, params)
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusUnprocessableEntity) {
+		return err
+	}
+	verr := &ValidationError{StatusCode: apiErr.StatusCode, Key: apiErr.Key, Message: apiErr.Message}
+	for _, f := range q.filters {
+		if strings.Contains(verr.Message, f.field) {
+			verr.Field = f.field
+			if strings.Contains(verr.Message, f.operator) {
+				verr.Operator = f.operator
+			}
+			break
+		}
+	}
+	return verr
+}
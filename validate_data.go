@@ -0,0 +1,205 @@
+package carthooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validationSchemaTTL is how long ValidateItemData keeps a collection's
+// schema when WithSchemaCacheTTL is not set.
+const validationSchemaTTL = time.Minute
+
+// FieldError is one problem ValidateItemData found with a field.
+type FieldError struct {
+	Field string
+	// Code is "required", "unknown", "type", "option" or "length".
+	Code    string
+	Message string
+}
+
+// FieldErrors lists the problems found by ValidateItemData, ordered by
+// field. It matches ErrInvalid with errors.Is.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+	}
+	return "carthooks: invalid item data: " + strings.Join(msgs, "; ")
+}
+
+func (e FieldErrors) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// ValidateItemData checks data for a new item against the collection's
+// schema without writing it: required fields must be set, every field must
+// exist, values must have the type of their field, select fields must hold
+// one of their options, by ID or label, and text must not exceed the
+// field's maxLength setting. Problems are returned as FieldErrors.
+//
+// Type checks cover text fields (text, textarea, email, url, phone), number
+// fields (number, money, percent, rating), checkbox and select and
+// multi-select fields; values of other types are not checked. The schema is
+// fetched with GetCollection and cached as set with WithSchemaCacheTTL, or
+// for a minute.
+func (c *Client) ValidateItemData(ctx context.Context, appID, collectionID int, data map[string]interface{}) error {
+	return c.validateItemData(ctx, appID, collectionID, data, false)
+}
+
+// ValidateItemUpdate is ValidateItemData for an update, which may leave out
+// required fields but not clear them.
+func (c *Client) ValidateItemUpdate(ctx context.Context, appID, collectionID int, data map[string]interface{}) error {
+	return c.validateItemData(ctx, appID, collectionID, data, true)
+}
+
+func (c *Client) validateItemData(ctx context.Context, appID, collectionID int, data map[string]interface{}, partial bool) error {
+	col, err := c.collectionSchema(ctx, appID, collectionID)
+	if err != nil {
+		return err
+	}
+	defs := make(map[string]*FieldDefinition, len(col.Fields))
+	for i := range col.Fields {
+		defs[col.Fields[i].Name] = &col.Fields[i]
+	}
+	var errs FieldErrors
+	for name, value := range data {
+		def, ok := defs[name]
+		if !ok {
+			errs = append(errs, FieldError{Field: name, Code: "unknown", Message: "no such field"})
+			continue
+		}
+		if isEmptyValue(value) {
+			if def.Required {
+				errs = append(errs, FieldError{Field: name, Code: "required", Message: "required field is empty"})
+			}
+			continue
+		}
+		if fe := checkFieldValue(def, value); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	if !partial {
+		for _, def := range col.Fields {
+			if _, ok := data[def.Name]; def.Required && !ok {
+				errs = append(errs, FieldError{Field: def.Name, Code: "required", Message: "required field is missing"})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// collectionSchema returns the collection with its fields, cached.
+func (c *Client) collectionSchema(ctx context.Context, appID, collectionID int) (*Collection, error) {
+	key := fmt.Sprintf("collection:%d:%d", appID, collectionID)
+	if v, ok := c.schema.get(key, c.clock.Now()); ok {
+		return v.(*Collection), nil
+	}
+	col, err := c.GetCollection(ctx, appID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	ttl := c.schemaTTL
+	if ttl <= 0 {
+		ttl = validationSchemaTTL
+	}
+	c.schema.set(key, col, c.clock.Now(), ttl)
+	return col, nil
+}
+
+// forgetSchema drops the cached schema and field options of a collection
+// whose fields changed.
+func (c *Client) forgetSchema(appID, collectionID int) {
+	c.schema.delete(fmt.Sprintf("collection:%d:%d", appID, collectionID))
+	c.schema.deletePrefix(fmt.Sprintf("options:%d:%d:", appID, collectionID))
+}
+
+func checkFieldValue(def *FieldDefinition, value interface{}) *FieldError {
+	typeError := func(want string) *FieldError {
+		return &FieldError{Field: def.Name, Code: "type", Message: fmt.Sprintf("%s field needs %s, got %T", def.Type, want, value)}
+	}
+	switch def.Type {
+	case "text", "textarea", "email", "url", "phone":
+		s, ok := value.(string)
+		if !ok {
+			return typeError("a string")
+		}
+		if max, ok := def.Settings["maxLength"].(float64); ok && utf8.RuneCountInString(s) > int(max) {
+			return &FieldError{Field: def.Name, Code: "length", Message: fmt.Sprintf("longer than %d characters", int(max))}
+		}
+	case "number", "money", "percent", "rating":
+		if !isNumber(value) {
+			return typeError("a number")
+		}
+	case "checkbox":
+		if _, ok := value.(bool); !ok {
+			return typeError("a boolean")
+		}
+	case "select":
+		s, ok := value.(string)
+		if !ok {
+			return typeError("a string")
+		}
+		if len(def.Options) > 0 && !hasOption(def.Options, s) {
+			return &FieldError{Field: def.Name, Code: "option", Message: fmt.Sprintf("%q is not an option", s)}
+		}
+	case "multi-select":
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice {
+			return typeError("a list")
+		}
+		for i := 0; i < rv.Len(); i++ {
+			s, ok := rv.Index(i).Interface().(string)
+			if !ok {
+				return typeError("a list of strings")
+			}
+			if len(def.Options) > 0 && !hasOption(def.Options, s) {
+				return &FieldError{Field: def.Name, Code: "option", Message: fmt.Sprintf("%q is not an option", s)}
+			}
+		}
+	}
+	return nil
+}
+
+func isEmptyValue(v interface{}) bool {
+	if v == nil || v == "" {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0
+}
+
+func isNumber(v interface{}) bool {
+	if _, ok := v.(json.Number); ok {
+		return true
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// hasOption reports whether s is the ID or label of one of options,
+// including the nested options of cascading selects.
+func hasOption(options []FieldOption, s string) bool {
+	for _, o := range options {
+		if o.ID == s || o.Label == s || hasOption(o.Children, s) {
+			return true
+		}
+	}
+	return false
+}
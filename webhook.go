@@ -0,0 +1,96 @@
+package carthooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook signature headers.
+const (
+	WebhookSignatureHeader = "X-Carthooks-Signature"
+	WebhookTimestampHeader = "X-Carthooks-Timestamp"
+)
+
+// DefaultWebhookTolerance is how old a webhook's timestamp may be before
+// VerifyWebhook rejects it as a possible replay.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// MaxWebhookBody is the largest webhook body VerifyWebhookHandler reads.
+const MaxWebhookBody = 1 << 20
+
+var (
+	// ErrSignatureMismatch is returned for a webhook whose signature is
+	// missing or does not match its body.
+	ErrSignatureMismatch = errors.New("carthooks: webhook signature mismatch")
+	// ErrTimestampExpired is returned for a webhook whose timestamp is
+	// outside the tolerance, or malformed.
+	ErrTimestampExpired = errors.New("carthooks: webhook timestamp expired")
+)
+
+// VerifyWebhook checks that a webhook was signed with secret. The signature
+// header holds the hex HMAC-SHA256 of the raw body, optionally prefixed with
+// "sha256=". If the timestamp header (Unix seconds) is present, the signed
+// message is the timestamp, a dot and the body, and the timestamp must be
+// within DefaultWebhookTolerance of now.
+func VerifyWebhook(secret string, header http.Header, body []byte) error {
+	return VerifyWebhookAt(secret, header, body, time.Now(), DefaultWebhookTolerance)
+}
+
+// VerifyWebhookAt is VerifyWebhook with an explicit current time and replay
+// tolerance. A tolerance of zero or less disables the timestamp age check.
+func VerifyWebhookAt(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	signature := strings.TrimPrefix(strings.TrimSpace(header.Get(WebhookSignatureHeader)), "sha256=")
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return ErrSignatureMismatch
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	if ts := header.Get(WebhookTimestampHeader); ts != "" {
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return ErrTimestampExpired
+		}
+		if age := now.Sub(time.Unix(sec, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+			return ErrTimestampExpired
+		}
+		mac.Write([]byte(ts + "."))
+	}
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// VerifyWebhookHandler wraps next so that it only sees webhooks verified
+// with VerifyWebhook; others are answered with 401 Unauthorized, and bodies
+// larger than MaxWebhookBody with 413. The body is buffered for verification
+// and passed on unchanged.
+func VerifyWebhookHandler(secret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxWebhookBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "cannot read body", http.StatusBadRequest)
+			return
+		}
+		if err := VerifyWebhook(secret, r.Header, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
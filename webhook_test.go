@@ -0,0 +1,89 @@
+package carthooks_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+func signWebhook(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookAt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := `{"event":"item.created"}`
+	for _, tt := range []struct {
+		name      string
+		signature string
+		timestamp string
+		body      string
+		want      error
+	}{
+		{"signed body", signWebhook("secret", body), "", body, nil},
+		{"prefixed signature", "sha256=" + signWebhook("secret", body), "", body, nil},
+		{"signed timestamp", signWebhook("secret", ts+"."+body), ts, body, nil},
+		{"missing signature", "", "", body, carthooks.ErrSignatureMismatch},
+		{"malformed signature", "not hex", "", body, carthooks.ErrSignatureMismatch},
+		{"wrong secret", signWebhook("other", body), "", body, carthooks.ErrSignatureMismatch},
+		{"tampered body", signWebhook("secret", body), "", body + " ", carthooks.ErrSignatureMismatch},
+		{"unsigned timestamp", signWebhook("secret", body), ts, body, carthooks.ErrSignatureMismatch},
+		{"old timestamp", signWebhook("secret", "1699999000."+body), "1699999000", body, carthooks.ErrTimestampExpired},
+		{"future timestamp", signWebhook("secret", "1700001000."+body), "1700001000", body, carthooks.ErrTimestampExpired},
+		{"malformed timestamp", signWebhook("secret", "soon."+body), "soon", body, carthooks.ErrTimestampExpired},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.signature != "" {
+				header.Set(carthooks.WebhookSignatureHeader, tt.signature)
+			}
+			if tt.timestamp != "" {
+				header.Set(carthooks.WebhookTimestampHeader, tt.timestamp)
+			}
+			err := carthooks.VerifyWebhookAt("secret", header, []byte(tt.body), now, carthooks.DefaultWebhookTolerance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhookHandler(t *testing.T) {
+	var got string
+	h := carthooks.VerifyWebhookHandler("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got = string(data)
+	}))
+	serve := func(body, signature string) int {
+		r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+		r.Header.Set(carthooks.WebhookSignatureHeader, signature)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	body := `{"event":"item.created"}`
+	if code := serve(body, signWebhook("secret", body)); code != http.StatusOK || got != body {
+		t.Errorf("got %d with body %q, want 200 with %q", code, got, body)
+	}
+	if code := serve(body, signWebhook("other", body)); code != http.StatusUnauthorized {
+		t.Errorf("got %d for a bad signature, want 401", code)
+	}
+	large := strings.Repeat("x", carthooks.MaxWebhookBody+1)
+	if code := serve(large, signWebhook("secret", large)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("got %d for an oversized body, want 413", code)
+	}
+}
@@ -0,0 +1,158 @@
+// Package webhooks receives Carthooks webhook events over net/http.
+//
+// A Handler verifies each delivery with carthooks.VerifyWebhook, decodes it
+// into an Event and passes it to a function:
+//
+//	h := webhooks.NewHandler(secret, func(ctx context.Context, e webhooks.Event) error {
+//		switch e.Type {
+//		case webhooks.ItemCreated:
+//			...
+//		}
+//		return nil
+//	})
+//	http.Handle("/hooks/carthooks", h)
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+)
+
+// EventType is the kind of change an event reports.
+type EventType string
+
+const (
+	ItemCreated EventType = "item.created"
+	ItemUpdated EventType = "item.updated"
+	ItemDeleted EventType = "item.deleted"
+)
+
+// Event is one webhook delivery.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         EventType `json:"type"`
+	AppID        int       `json:"appId"`
+	CollectionID int       `json:"collectionId"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Item is the item the event is about. For ItemDeleted it may only
+	// carry the ID.
+	Item *carthooks.Item `json:"item"`
+	// Data is the raw "data" object of the payload, for event types and
+	// details the typed fields do not cover.
+	Data json.RawMessage `json:"data"`
+}
+
+// Parse decodes a webhook body into an Event. The item is read from
+// "data.item" or, failing that, from "data" itself.
+func Parse(body []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		return Event{}, err
+	}
+	if e.Item == nil && len(e.Data) > 0 {
+		var data struct {
+			Item *carthooks.Item `json:"item"`
+		}
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return Event{}, err
+		}
+		e.Item = data.Item
+		if e.Item == nil {
+			var item carthooks.Item
+			if err := json.Unmarshal(e.Data, &item); err == nil && item.ID != 0 {
+				e.Item = &item
+			}
+		}
+	}
+	if e.Type == "" {
+		return Event{}, errors.New("webhooks: event has no type")
+	}
+	return e, nil
+}
+
+// Handler is an http.Handler for webhook deliveries.
+type Handler struct {
+	secret    string
+	fn        func(context.Context, Event) error
+	tolerance time.Duration
+	now       func() time.Time
+	maxBody   int64
+}
+
+// DefaultMaxBody is the largest webhook body a Handler reads.
+const DefaultMaxBody = carthooks.MaxWebhookBody
+
+// Option customizes a Handler.
+type Option func(*Handler)
+
+// WithTolerance sets how old a delivery's timestamp may be, instead of
+// carthooks.DefaultWebhookTolerance. Zero or less disables the check.
+func WithTolerance(d time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = d
+	}
+}
+
+// WithMaxBody limits the size of the bodies the handler reads; larger ones
+// are rejected with 413.
+func WithMaxBody(n int64) Option {
+	return func(h *Handler) {
+		h.maxBody = n
+	}
+}
+
+// NewHandler returns a Handler that calls fn with each verified event.
+//
+// Deliveries with a bad signature or a stale timestamp are answered with 401,
+// and malformed payloads with 400, without calling fn. If fn returns an
+// error the handler answers 500 so the sender can retry; otherwise 204.
+func NewHandler(secret string, fn func(ctx context.Context, e Event) error, opts ...Option) *Handler {
+	h := &Handler{
+		secret:    secret,
+		fn:        fn,
+		tolerance: carthooks.DefaultWebhookTolerance,
+		now:       time.Now,
+		maxBody:   DefaultMaxBody,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.maxBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := carthooks.VerifyWebhookAt(h.secret, r.Header, body, h.now(), h.tolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	e, err := Parse(body)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if err := h.fn(r.Context(), e); err != nil {
+		http.Error(w, "webhook handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
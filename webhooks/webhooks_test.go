@@ -0,0 +1,109 @@
+package webhooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	carthooks "github.com/carthooks/carthooks-sdk-golang"
+	"github.com/carthooks/carthooks-sdk-golang/webhooks"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		body     string
+		wantType webhooks.EventType
+		wantItem int
+	}{
+		{"created with data.item", `{"id":"e1","type":"item.created","appId":1,"collectionId":2,"data":{"item":{"id":7,"fields":{"title":"a"}}}}`, webhooks.ItemCreated, 7},
+		{"updated with bare data", `{"id":"e2","type":"item.updated","appId":1,"collectionId":2,"data":{"id":8,"fields":{"title":"b"}}}`, webhooks.ItemUpdated, 8},
+		{"deleted with only the ID", `{"id":"e3","type":"item.deleted","data":{"id":9}}`, webhooks.ItemDeleted, 9},
+		{"other type without item", `{"id":"e4","type":"collection.updated","data":{"name":"orders"}}`, "collection.updated", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := webhooks.Parse([]byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", e.Type, tt.wantType)
+			}
+			switch {
+			case tt.wantItem == 0 && e.Item != nil:
+				t.Errorf("Item = %+v, want none", e.Item)
+			case tt.wantItem != 0 && (e.Item == nil || e.Item.ID != tt.wantItem):
+				t.Errorf("Item = %+v, want item %d", e.Item, tt.wantItem)
+			}
+			if len(e.Data) == 0 {
+				t.Error("Data is empty, want the raw data object")
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, body := range map[string]string{
+		"missing type": `{"id":"e1","data":{"item":{"id":7}}}`,
+		"not JSON":     `item.created`,
+	} {
+		if _, err := webhooks.Parse([]byte(body)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	const valid = `{"id":"e1","type":"item.created","data":{"item":{"id":7,"fields":{}}}}`
+	for _, tt := range []struct {
+		name      string
+		method    string
+		body      string
+		signature string
+		fnErr     error
+		want      int
+		wantCall  bool
+	}{
+		{"delivered", http.MethodPost, valid, sign("secret", valid), nil, http.StatusNoContent, true},
+		{"callback failed", http.MethodPost, valid, sign("secret", valid), errors.New("db down"), http.StatusInternalServerError, true},
+		{"bad signature", http.MethodPost, valid, sign("other", valid), nil, http.StatusUnauthorized, false},
+		{"malformed payload", http.MethodPost, `{"id":"e1"}`, sign("secret", `{"id":"e1"}`), nil, http.StatusBadRequest, false},
+		{"wrong method", http.MethodGet, "", "", nil, http.StatusMethodNotAllowed, false},
+		{"too large", http.MethodPost, strings.Repeat(" ", 65) + valid, sign("secret", strings.Repeat(" ", 65)+valid), nil, http.StatusRequestEntityTooLarge, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			h := webhooks.NewHandler("secret", func(ctx context.Context, e webhooks.Event) error {
+				called = true
+				if e.Type != webhooks.ItemCreated || e.Item == nil || e.Item.ID != 7 {
+					t.Errorf("event = %+v, want item.created for item 7", e)
+				}
+				return tt.fnErr
+			}, webhooks.WithMaxBody(int64(len(valid)+64)))
+			r := httptest.NewRequest(tt.method, "/hooks", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				r.Header.Set(carthooks.WebhookSignatureHeader, tt.signature)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if called != tt.wantCall {
+				t.Errorf("callback called = %v, want %v", called, tt.wantCall)
+			}
+		})
+	}
+}
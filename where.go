@@ -0,0 +1,111 @@
+package carthooks
+
+import (
+	"reflect"
+)
+
+// Filter operators understood by the API, for use with Filter, FilterValues
+// and Where.
+const (
+	OpEq           = "$eq"
+	OpEqi          = "$eqi"
+	OpNe           = "$ne"
+	OpNei          = "$nei"
+	OpLt           = "$lt"
+	OpLte          = "$lte"
+	OpGt           = "$gt"
+	OpGte          = "$gte"
+	OpIn           = "$in"
+	OpNotIn        = "$notIn"
+	OpBetween      = "$between"
+	OpContains     = "$contains"
+	OpNotContains  = "$notContains"
+	OpContainsi    = "$containsi"
+	OpNotContainsi = "$notContainsi"
+	OpStartsWith   = "$startsWith"
+	OpStartsWithi  = "$startsWithi"
+	OpEndsWith     = "$endsWith"
+	OpEndsWithi    = "$endsWithi"
+	OpNull         = "$null"
+	OpNotNull      = "$notNull"
+)
+
+// multiValueOperators take a list of values.
+var multiValueOperators = map[string]bool{
+	OpIn: true, OpNotIn: true, OpBetween: true,
+}
+
+// Where adds the condition field <operator> value like Filter, but checks the
+// operator and formats a typed value: strings, bools, integers, floats,
+// json.Number and time.Time (sent as RFC 3339 in UTC). For OpIn, OpNotIn and
+// OpBetween, value may be a slice of those, as for FilterValues; OpBetween
+// needs exactly two.
+//
+// An unknown operator or unsupported value is not sent: the query's requests
+// fail with a *ValidationError instead, also returned by Err. Conditions in
+// nested groups are built the same way, e.g.
+//
+//	q.Where("status", carthooks.OpIn, []string{"open", "pending"}).
+//		Or(
+//			func(b *carthooks.Query) { b.Where("priority", carthooks.OpGte, 3) },
+//			func(b *carthooks.Query) { b.WhereNull("dueDate") },
+//		)
+func (q *Query) Where(field, operator string, value interface{}) *Query {
+	if q.err != nil {
+		return q
+	}
+	if err := validateFilter(field, operator); err != nil {
+		q.err = err
+		return q
+	}
+	fail := func(msg string) *Query {
+		q.err = &ValidationError{Field: field, Operator: operator, Message: msg}
+		return q
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		if !multiValueOperators[operator] {
+			return fail("operator takes a single value")
+		}
+		values := make([]string, rv.Len())
+		for i := range values {
+			v, err := filterValue(rv.Index(i).Interface())
+			if err != nil {
+				return fail(err.Error())
+			}
+			values[i] = v
+		}
+		if operator == OpBetween && len(values) != 2 {
+			return fail("$between takes two values")
+		}
+		return q.FilterValues(field, operator, values)
+	}
+
+	v, err := filterValue(value)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if operator == OpBetween {
+		return fail("$between takes two values")
+	}
+	if multiValueOperators[operator] {
+		return q.FilterValues(field, operator, []string{v})
+	}
+	return q.Filter(field, operator, v)
+}
+
+// WhereNull matches items whose field is empty.
+func (q *Query) WhereNull(field string) *Query {
+	return q.Filter(field, OpNull, "true")
+}
+
+// WhereNotNull matches items whose field has a value.
+func (q *Query) WhereNotNull(field string) *Query {
+	return q.Filter(field, OpNotNull, "true")
+}
+
+// Err returns the first invalid condition added with Where, if any.
+func (q *Query) Err() error {
+	return q.err
+}
@@ -0,0 +1,37 @@
+package carthooks
+
+import "net/http"
+
+// WriteOption customizes a mutating request such as DeleteItem.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	header http.Header
+}
+
+func newWriteOptions(opts []WriteOption) writeOptions {
+	o := writeOptions{header: http.Header{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// IfMatch makes the write conditional on the item still having the given
+// version (ETag). If it changed in the meantime the call fails with a
+// *ConflictError carrying the current version.
+func IfMatch(version string) WriteOption {
+	return func(o *writeOptions) {
+		o.header.Set("If-Match", version)
+	}
+}
+
+// WithIdempotencyKey sends key as the request's Idempotency-Key, so that
+// repeating the write with the same key, by WithRetry or by the caller, is
+// not applied twice where the server honors the header. It also lets
+// WithRetry retry a POST, e.g. one whose response was lost to a timeout.
+func WithIdempotencyKey(key string) WriteOption {
+	return func(o *writeOptions) {
+		o.header.Set(idempotencyKeyHeader, key)
+	}
+}